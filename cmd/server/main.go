@@ -3,134 +3,279 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"slices"
 	"strings"
+	"syscall"
+	"time"
 
 	gorilla "github.com/gorilla/mux"
-	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	_ "github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
 
+	"go-backend-service/internal/auth"
+	"go-backend-service/internal/config"
+	"go-backend-service/internal/domain"
+	"go-backend-service/internal/health"
+	"go-backend-service/internal/logging"
+	"go-backend-service/internal/metrics"
+	"go-backend-service/internal/redact"
+	"go-backend-service/internal/repository"
 	"go-backend-service/internal/server"
+	"go-backend-service/internal/tracing"
+	"go-backend-service/internal/usecase"
+	"go-backend-service/migrations"
+	"go-backend-service/pkg/pb"
 )
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+func main() {
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "path to an optional YAML config file")
+	printConfig := flag.Bool("print-config", false, "print the effective configuration (secrets redacted) and exit")
+	flag.Parse()
+
+	// Structured JSON logs; the standard log package is routed here too.
+	// Credentials are masked by the handler.
+	logLevel := new(slog.LevelVar)
+	logger := logging.New(os.Stderr, logLevel)
+	slog.SetDefault(logger)
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fatal("failed to load configuration", err)
 	}
-	return defaultValue
-}
+	if *printConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			fatal("failed to print configuration", err)
+		}
+		return
+	}
+	level, err := logging.ParseLevel(cfg.Log.Level)
+	if err != nil {
+		fatal("invalid log level", err)
+	}
+	logLevel.Set(level)
+	redact.RegisterSensitiveFields(cfg.Log.RedactFields...)
 
-func getDatabaseURL() string {
-	host := getEnv("DB_HOST", "localhost")
-	port := getEnv("DB_PORT", "5432")
-	user := getEnv("DB_USER", "postgres")
-	password := getEnv("DB_PASSWORD", "passDblocal")
-	dbname := getEnv("DB_NAME", "grpc_product")
-	
-	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
-		user, password, host, port, dbname)
-}
+	logger.Info("starting server",
+		"http_addr", cfg.Server.HTTPAddr(),
+		"grpc_addr", cfg.Server.GRPCAddr(),
+		"database", redact.DSN(cfg.Database.URL()),
+		"log_level", level.String(),
+	)
+
+	tracingCtx, cancelTracing := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	shutdownTracing, err := tracing.Setup(tracingCtx, cfg.Tracing)
+	cancelTracing()
+	if err != nil {
+		fatal("failed to set up tracing", err)
+	}
 
-func main() {
-	// Get configuration from environment
-	grpcPort := getEnv("GRPC_PORT", "50051")
-	httpPort := getEnv("HTTP_PORT", "8080")
-	
-	// Add colon prefix if not present
-	if !strings.HasPrefix(grpcPort, ":") {
-		grpcPort = ":" + grpcPort
-	}
-	if !strings.HasPrefix(httpPort, ":") {
-		httpPort = ":" + httpPort
-	}
-	dbConnStr := getDatabaseURL()
-	
-	log.Printf("🚀 Starting Go gRPC Backend Server with config:")
-	log.Printf("  📡 HTTP Port: %s", httpPort)
-	log.Printf("  🔧 gRPC Port: %s", grpcPort)
-	log.Printf("  💾 Database: %s", getDatabaseURL())
-	
 	// Initialize DB
-	db, err := sql.Open("postgres", dbConnStr)
+	db, err := sql.Open("postgres", cfg.Database.URL())
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		fatal("failed to open database", err)
 	}
-	defer db.Close()
+	db.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.Database.ConnMaxIdleTime)
 
 	// Test database connection
 	if err := db.Ping(); err != nil {
-		log.Fatalf("Failed to ping database: %v", err)
+		fatal("failed to ping database", err)
 	}
-	log.Println("Database connection established successfully")
+	logger.Info("database connection established")
 
-	go server.StartGRPCServer(db, grpcPort)
+	// Stop on SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	/*
-		// Initialize dependencies
-		productRepo := repository.NewPostgresProductRepository(db)
-		productUsecase := usecase.NewProductUsecase(productRepo)
-		productHandler := grpc.NewProductHandler(productUsecase)
+	serveErr := make(chan error, 2)
 
-		// Start gRPC server
-		go func() {
-			lis, err := net.Listen("tcp", grpcPort)
-			if err != nil {
-				log.Fatalf("Failed to listen: %v", err)
-			}
+	serverCreds, err := server.ServerCredentials(cfg.TLS)
+	if err != nil {
+		fatal("failed to load TLS credentials", err)
+	}
+	migrationVersion, err := migrations.LatestVersion()
+	if err != nil {
+		fatal("failed to read migrations", err)
+	}
+	checker := health.NewChecker(db, migrationVersion)
+	go checker.Run(ctx, cfg.Server.HealthCheckInterval)
 
-			grpcServer := grpcpkg.NewServer()
-			pb.RegisterProductServiceServer(grpcServer, productHandler)
+	m := metrics.New(db)
+	m.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Name: "panics_recovered_total",
+		Help: "Number of panics recovered in gRPC and HTTP handlers.",
+	}, func() float64 { return float64(server.PanicCount()) }))
 
-			log.Printf("Starting gRPC server on port %s", grpcPort)
-			if err := grpcServer.Serve(lis); err != nil {
-				log.Fatalf("Failed to serve: %v", err)
+	var authn *auth.Authenticator
+	var policy *auth.Policy
+	if cfg.Auth.Enabled {
+		var apiKeys auth.APIKeyVerifier
+		if cfg.Auth.APIKeys {
+			apiKeyUsecase := usecase.NewAPIKeyUsecase(repository.NewPostgresAPIKeyRepository(db))
+			if err := bootstrapAPIKey(ctx, cfg.Auth, apiKeyUsecase, logger); err != nil {
+				fatal("failed to bootstrap API keys", err)
 			}
-		}()
-	*/
+			apiKeys = apiKeyUsecase
+		}
+		authn, err = auth.NewAuthenticator(ctx, cfg.Auth, apiKeys)
+		if err != nil {
+			fatal("failed to set up authentication", err)
+		}
+		go authn.Run(ctx, cfg.Auth.JWKSRefreshInterval)
+		policy = auth.NewPolicy(cfg.Auth.MethodRoles, pb.File_pkg_pb_product_proto, pb.File_pkg_pb_dipan_type_proto, pb.File_pkg_pb_api_key_proto)
+	} else {
+		logger.Warn("authentication is disabled; every RPC is open and ApiKeyService is not served")
+	}
+
+	var limiter *server.RateLimiter
+	if cfg.RateLimit.Enabled {
+		limiter = server.NewRateLimiter(cfg.RateLimit)
+		go limiter.Run(ctx, cfg.RateLimit.CleanupInterval)
+	}
+
+	sweeper := usecase.NewReservationSweeper(repository.NewPostgresStockRepository(db))
+	go sweeper.Run(ctx, cfg.Stock.ReservationSweepInterval)
+
+	grpcServer := server.NewGRPCServer(db, checker, logger, m, authn, policy, limiter, grpc.Creds(serverCreds))
+	go func() {
+		if err := server.StartGRPCServer(grpcServer, cfg.Server.GRPCAddr()); err != nil {
+			serveErr <- fmt.Errorf("gRPC server: %w", err)
+		}
+	}()
+
 	// Start HTTP server (gRPC-Gateway)
-	ctx := context.Background()
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
+	gatewayCtx, cancelGateway := context.WithCancel(context.Background())
+	defer cancelGateway()
 
-	mux := runtime.NewServeMux()
+	clientCreds, err := server.ClientCredentials(cfg.TLS)
+	if err != nil {
+		fatal("failed to load TLS credentials", err)
+	}
+	mux := server.NewGatewayMux()
 	// In Docker, services communicate using service names
-	grpcHost := getEnv("GRPC_HOST", "localhost")
-	grpcEndpoint := grpcHost + grpcPort
-	server.StartHTTPGateway(ctx, mux, db, grpcEndpoint)
-	/*
-		opts := []grpcpkg.DialOption{grpcpkg.WithTransportCredentials(insecure.NewCredentials())}
-
-		if err := pb.RegisterProductServiceHandlerFromEndpoint(
-			ctx,
-			mux,
-			"localhost"+grpcPort,
-			opts,
-		); err != nil {
-			log.Fatalf("Failed to register gateway: %v", err)
-		}
-	*/
+	if err := server.StartHTTPGateway(gatewayCtx, mux, db, cfg.Server.GRPCEndpoint(), clientCreds); err != nil {
+		fatal("failed to start HTTP gateway", err)
+	}
+
 	// Router untuk Swagger UI
-	router := muxWithSwagger(mux)
+	router := muxWithSwagger(mux, cfg.CORS.AllowedOrigins, checker, m, limiter, logger)
 
-	log.Printf("Starting HTTP server on port %s", httpPort)
-	if err := http.ListenAndServe(httpPort, router); err != nil {
-		log.Fatalf("Failed to serve HTTP: %v", err)
+	httpServer := &http.Server{
+		Addr:              cfg.Server.HTTPAddr(),
+		Handler:           server.TracingHandler(router),
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
 	}
+	go func() {
+		logger.Info("HTTP server listening", "addr", httpServer.Addr)
+		var err error
+		if cfg.TLS.Enabled {
+			err = httpServer.ListenAndServeTLS(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+		} else {
+			err = httpServer.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- fmt.Errorf("HTTP server: %w", err)
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		logger.Info("shutdown signal received")
+	case err := <-serveErr:
+		logger.Error("server error", "error", err)
+	}
+
+	// Drain HTTP first: its requests still need the gRPC server
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancelShutdown()
+
+	logger.Info("shutting down", "timeout", cfg.Server.ShutdownTimeout.String())
+	checker.Shutdown()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		logger.Error("HTTP server shutdown", "error", err)
+	}
+	server.StopGRPCServer(shutdownCtx, grpcServer)
+	cancelGateway()
+
+	if err := db.Close(); err != nil {
+		logger.Error("database close", "error", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Error("tracing shutdown", "error", err)
+	}
+	logger.Info("server stopped")
+}
+
+// fatal logs err and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// bootstrapAPIKey stores AUTH_BOOTSTRAP_API_KEY as an admin key. Without it,
+// it refuses to start when API keys are the only way in and no active admin
+// key exists, since CreateApiKey itself needs the admin role.
+func bootstrapAPIKey(ctx context.Context, cfg config.AuthConfig, apiKeys domain.APIKeyUsecase, logger *slog.Logger) error {
+	if cfg.BootstrapAPIKey != "" {
+		created, err := apiKeys.EnsureAPIKey(ctx, &domain.APIKey{Name: "bootstrap", Scopes: []string{"admin"}}, cfg.BootstrapAPIKey)
+		if err != nil {
+			return err
+		}
+		if created {
+			logger.Warn("stored AUTH_BOOTSTRAP_API_KEY as an admin API key; revoke it once real keys are issued")
+		}
+		return nil
+	}
+	if cfg.HS256Secret != "" || cfg.JWKSFile != "" || cfg.JWKSURL != "" {
+		return nil
+	}
+
+	keys, err := apiKeys.ListAPIKeys(ctx, false)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, key := range keys {
+		if key.Active(now) && slices.Contains(key.Scopes, "admin") {
+			return nil
+		}
+	}
+	return errors.New("no active admin API key and no JWT issuer: set AUTH_BOOTSTRAP_API_KEY, AUTH_HS256_SECRET or AUTH_JWKS_URL")
 }
 
 // muxWithSwagger menambahkan handler Swagger ke router
-func muxWithSwagger(mux http.Handler) http.Handler {
+func muxWithSwagger(mux http.Handler, allowedOrigins []string, checker *health.Checker, m *metrics.Metrics, limiter *server.RateLimiter, logger *slog.Logger) http.Handler {
 	r := gorilla.NewRouter()
 
+	// Request id, request logging, metrics and panic recovery
+	r.Use(server.RequestIDMiddleware)
+	r.Use(server.LoggingMiddleware(logger))
+	r.Use(server.MetricsMiddleware(m))
+	r.Use(server.RecoveryMiddleware)
+
 	// CORS Middleware
 	corsMiddleware := func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, Authorization, X-CSRF-Token")
+			if origin := allowedOrigin(allowedOrigins, r.Header.Get("Origin")); origin != "" {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+			w.Header().Add("Vary", "Origin")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, Authorization, X-CSRF-Token, X-Request-ID, X-Api-Key, If-Match")
+			w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, Retry-After, ETag")
 
 			if r.Method == "OPTIONS" {
 				w.WriteHeader(http.StatusOK)
@@ -144,23 +289,35 @@ func muxWithSwagger(mux http.Handler) http.Handler {
 	// Apply CORS middleware to all routes
 	r.Use(corsMiddleware)
 
+	// Per-IP rate limit, after CORS so that browsers can read a 429
+	if limiter != nil {
+		r.Use(limiter.Middleware)
+	}
+
 	// Serve gRPC-Gateway API
 	r.PathPrefix("/v1/").Handler(mux)
 
+	// Liveness and readiness probes
+	r.Handle("/healthz", checker.LivenessHandler()).Methods("GET")
+	r.Handle("/readyz", checker.ReadinessHandler()).Methods("GET")
+
+	// Prometheus metrics
+	r.Handle("/metrics", m.Handler()).Methods("GET")
+
 	// Serve Swagger JSON
 	r.HandleFunc("/swagger.json", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
 		filePath := "docs/backend_api.swagger.json"
 		if _, err := os.Stat(filePath); os.IsNotExist(err) {
-			log.Printf("Swagger file not found at: %s", filePath)
+			logging.FromContext(r.Context()).Warn("swagger file not found", "path", filePath)
 			http.Error(w, "Swagger file not found", http.StatusNotFound)
 			return
 		}
 
 		data, err := os.ReadFile(filePath)
 		if err != nil {
-			log.Printf("Error reading swagger file: %v", err)
+			logging.FromContext(r.Context()).Error("error reading swagger file", "error", err)
 			http.Error(w, "Error reading swagger file", http.StatusInternalServerError)
 			return
 		}
@@ -179,3 +336,17 @@ func muxWithSwagger(mux http.Handler) http.Handler {
 
 	return r
 }
+
+// allowedOrigin returns the Access-Control-Allow-Origin value for origin, or
+// "" when the origin is not allowed.
+func allowedOrigin(allowedOrigins []string, origin string) string {
+	for _, allowed := range allowedOrigins {
+		if allowed == "*" {
+			return "*"
+		}
+		if origin != "" && strings.EqualFold(allowed, origin) {
+			return origin
+		}
+	}
+	return ""
+}
@@ -0,0 +1,173 @@
+// Package auth authenticates callers from JWT bearer tokens and carries the
+// resulting claims through context.Context.
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-backend-service/internal/config"
+	"go-backend-service/internal/domain"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// leeway tolerates clock skew between the token issuer and this service.
+const leeway = 30 * time.Second
+
+// APIKeySubjectPrefix starts the subject of callers authenticated by API key.
+const APIKeySubjectPrefix = "apikey:"
+
+var (
+	ErrMissingCredentials = domain.NewError(domain.ErrUnauthenticated, "MISSING_CREDENTIALS", "missing bearer token or API key")
+	ErrInvalidToken       = domain.NewError(domain.ErrUnauthenticated, "INVALID_TOKEN", "invalid or expired token")
+)
+
+// Claims identify an authenticated caller.
+type Claims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles,omitempty"`
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying claims.
+func NewContext(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, contextKey{}, claims)
+}
+
+// FromContext returns the claims of the authenticated caller, if any.
+func FromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(contextKey{}).(*Claims)
+	return claims, ok
+}
+
+// APIKeyVerifier resolves an x-api-key value to an active key.
+// domain.APIKeyUsecase implements it.
+type APIKeyVerifier interface {
+	VerifyAPIKey(ctx context.Context, key string) (*domain.APIKey, error)
+}
+
+// Authenticator verifies HS256 tokens signed with a shared secret, RS256
+// tokens signed by a key from a JWKS document, and API keys.
+type Authenticator struct {
+	secret  []byte
+	jwks    *jwks
+	parser  *jwt.Parser
+	apiKeys APIKeyVerifier
+	public  map[string]bool
+}
+
+// NewAuthenticator loads the verification keys described by cfg. A JWKS URL
+// is fetched once here; call Run to keep it fresh. A nil apiKeys disables
+// API key authentication.
+func NewAuthenticator(ctx context.Context, cfg config.AuthConfig, apiKeys APIKeyVerifier) (*Authenticator, error) {
+	a := &Authenticator{apiKeys: apiKeys, public: make(map[string]bool, len(cfg.PublicMethods))}
+	for _, method := range cfg.PublicMethods {
+		a.public[method] = true
+	}
+
+	var methods []string
+	if cfg.HS256Secret != "" {
+		a.secret = []byte(cfg.HS256Secret)
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if cfg.JWKSFile != "" || cfg.JWKSURL != "" {
+		a.jwks = newJWKS(cfg.JWKSFile, cfg.JWKSURL)
+		if err := a.jwks.load(ctx); err != nil {
+			return nil, fmt.Errorf("load JWKS: %w", err)
+		}
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	if len(methods) == 0 {
+		if apiKeys == nil {
+			return nil, fmt.Errorf("no JWT verification key or API key store configured")
+		}
+		// API keys only; every bearer token is rejected.
+		return a, nil
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(leeway),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+	a.parser = jwt.NewParser(opts...)
+	return a, nil
+}
+
+// Run refreshes a JWKS fetched from a URL every interval until ctx is done.
+func (a *Authenticator) Run(ctx context.Context, interval time.Duration) {
+	if a.jwks == nil || a.jwks.url == "" {
+		return
+	}
+	a.jwks.run(ctx, interval)
+}
+
+// IsPublic reports whether fullMethod (/package.Service/Method) may be called
+// without credentials. "/package.Service/*" makes a whole service public.
+func (a *Authenticator) IsPublic(fullMethod string) bool {
+	if a.public[fullMethod] {
+		return true
+	}
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return a.public[fullMethod[:i+1]+"*"]
+	}
+	return false
+}
+
+// Authenticate verifies the value of an authorization header ("Bearer
+// <token>") and returns its claims.
+func (a *Authenticator) Authenticate(authorization string) (*Claims, error) {
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "bearer") || token == "" {
+		return nil, ErrMissingCredentials
+	}
+	if a.parser == nil {
+		return nil, ErrInvalidToken
+	}
+
+	claims := &Claims{}
+	if _, err := a.parser.ParseWithClaims(token, claims, a.key); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if claims.Subject == "" {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func (a *Authenticator) key(token *jwt.Token) (any, error) {
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		return a.secret, nil
+	case jwt.SigningMethodRS256.Alg():
+		kid, _ := token.Header["kid"].(string)
+		return a.jwks.key(kid)
+	}
+	return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+}
+
+// AuthenticateAPIKey verifies the value of an x-api-key header. The key's
+// scopes become the caller's roles and its subject is "apikey:<id>".
+func (a *Authenticator) AuthenticateAPIKey(ctx context.Context, key string) (*Claims, error) {
+	if a.apiKeys == nil {
+		return nil, domain.ErrInvalidAPIKey
+	}
+	apiKey, err := a.apiKeys.VerifyAPIKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: APIKeySubjectPrefix + apiKey.ID},
+		Roles:            apiKey.Scopes,
+	}, nil
+}
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"slices"
+
+	"go-backend-service/internal/domain"
+	"go-backend-service/pkg/pb"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var ErrPermissionDenied = domain.NewError(domain.ErrPermissionDenied, "PERMISSION_DENIED", "caller is not allowed to call this method")
+
+// Policy maps full gRPC method names to the roles allowed to call them.
+// Methods without an entry only require authentication.
+type Policy struct {
+	roles map[string][]string
+}
+
+// NewPolicy collects the (pb.access) method options declared in files, then
+// applies overrides, which replace or add the roles of a method.
+func NewPolicy(overrides map[string][]string, files ...protoreflect.FileDescriptor) *Policy {
+	p := &Policy{roles: make(map[string][]string)}
+	for _, file := range files {
+		services := file.Services()
+		for i := 0; i < services.Len(); i++ {
+			methods := services.Get(i).Methods()
+			for j := 0; j < methods.Len(); j++ {
+				method := methods.Get(j)
+				access, ok := proto.GetExtension(method.Options(), pb.E_Access).(*pb.AccessPolicy)
+				if !ok || access == nil {
+					continue
+				}
+				p.roles[fullMethodName(method)] = access.GetRoles()
+			}
+		}
+	}
+	for method, roles := range overrides {
+		p.roles[method] = roles
+	}
+	return p
+}
+
+// Roles returns the roles allowed to call fullMethod and whether the method
+// has a policy at all.
+func (p *Policy) Roles(fullMethod string) ([]string, bool) {
+	roles, ok := p.roles[fullMethod]
+	return roles, ok
+}
+
+// Authorize returns ErrPermissionDenied unless claims hold one of the roles
+// allowed to call fullMethod.
+func (p *Policy) Authorize(fullMethod string, claims *Claims) error {
+	allowed, ok := p.roles[fullMethod]
+	if !ok {
+		return nil
+	}
+	for _, role := range claims.Roles {
+		if slices.Contains(allowed, role) {
+			return nil
+		}
+	}
+	return ErrPermissionDenied
+}
+
+// fullMethodName returns the /package.Service/Method name gRPC uses for md.
+func fullMethodName(md protoreflect.MethodDescriptor) string {
+	return "/" + string(md.Parent().FullName()) + "/" + string(md.Name())
+}
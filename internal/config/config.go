@@ -0,0 +1,452 @@
+// Package config loads the service configuration from defaults, an optional
+// YAML file and environment variables, in that order of precedence.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	Server    ServerConfig    `yaml:"server"`
+	Database  DatabaseConfig  `yaml:"database"`
+	TLS       TLSConfig       `yaml:"tls"`
+	CORS      CORSConfig      `yaml:"cors"`
+	Log       LogConfig       `yaml:"log"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	Auth      AuthConfig      `yaml:"auth"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Stock     StockConfig     `yaml:"stock"`
+}
+
+type ServerConfig struct {
+	HTTPPort          int           `yaml:"http_port" env:"HTTP_PORT"`
+	GRPCPort          int           `yaml:"grpc_port" env:"GRPC_PORT"`
+	GRPCHost          string        `yaml:"grpc_host" env:"GRPC_HOST"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT"`
+	// HealthCheckInterval is how often the gRPC health status is refreshed.
+	HealthCheckInterval time.Duration `yaml:"health_check_interval" env:"HEALTH_CHECK_INTERVAL"`
+}
+
+// HTTPAddr is the listen address of the HTTP gateway, e.g. ":8080".
+func (c ServerConfig) HTTPAddr() string {
+	return ":" + strconv.Itoa(c.HTTPPort)
+}
+
+// GRPCAddr is the listen address of the gRPC server, e.g. ":50051".
+func (c ServerConfig) GRPCAddr() string {
+	return ":" + strconv.Itoa(c.GRPCPort)
+}
+
+// GRPCEndpoint is the address the HTTP gateway dials to reach the gRPC server.
+func (c ServerConfig) GRPCEndpoint() string {
+	return net.JoinHostPort(c.GRPCHost, strconv.Itoa(c.GRPCPort))
+}
+
+type DatabaseConfig struct {
+	Host            string        `yaml:"host" env:"DB_HOST"`
+	Port            int           `yaml:"port" env:"DB_PORT"`
+	User            string        `yaml:"user" env:"DB_USER"`
+	Password        string        `yaml:"password" env:"DB_PASSWORD" secret:"true"`
+	Name            string        `yaml:"name" env:"DB_NAME"`
+	SSLMode         string        `yaml:"sslmode" env:"DB_SSLMODE"`
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"`
+}
+
+// URL returns the lib/pq connection string. It contains the password and
+// must not be logged.
+func (c DatabaseConfig) URL() string {
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.User, c.Password),
+		Host:     net.JoinHostPort(c.Host, strconv.Itoa(c.Port)),
+		Path:     "/" + c.Name,
+		RawQuery: url.Values{"sslmode": {c.SSLMode}}.Encode(),
+	}
+	return u.String()
+}
+
+// TLSConfig enables TLS on both the gRPC server and the HTTP gateway.
+type TLSConfig struct {
+	Enabled  bool   `yaml:"enabled" env:"TLS_ENABLED"`
+	CertFile string `yaml:"cert_file" env:"TLS_CERT_FILE"`
+	KeyFile  string `yaml:"key_file" env:"TLS_KEY_FILE"`
+	// CAFile verifies the gRPC server when the gateway dials it. When empty
+	// the system roots are used.
+	CAFile string `yaml:"ca_file" env:"TLS_CA_FILE"`
+}
+
+type CORSConfig struct {
+	// AllowedOrigins is a comma-separated list in the environment.
+	// "*" allows any origin.
+	AllowedOrigins []string `yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
+}
+
+type LogConfig struct {
+	Level string `yaml:"level" env:"LOG_LEVEL"`
+	// RedactFields lists additional proto fields (full names) masked in logs.
+	RedactFields []string `yaml:"redact_fields" env:"LOG_REDACT_FIELDS"`
+}
+
+type TracingConfig struct {
+	// Exporter is none, otlp or stdout.
+	Exporter    string `yaml:"exporter" env:"TRACING_EXPORTER"`
+	ServiceName string `yaml:"service_name" env:"TRACING_SERVICE_NAME"`
+	// OTLPEndpoint is the host:port of an OTLP/gRPC collector.
+	OTLPEndpoint string `yaml:"otlp_endpoint" env:"TRACING_OTLP_ENDPOINT"`
+	OTLPInsecure bool   `yaml:"otlp_insecure" env:"TRACING_OTLP_INSECURE"`
+	// SampleRatio is the fraction of new traces recorded, from 0 to 1.
+	// Incoming sampled traces are always recorded.
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+}
+
+// AuthConfig configures JWT bearer and API key authentication. It is enabled
+// by default; when enabled, at least one of HS256Secret, JWKSFile, JWKSURL or
+// APIKeys must be set.
+type AuthConfig struct {
+	Enabled     bool   `yaml:"enabled" env:"AUTH_ENABLED"`
+	HS256Secret string `yaml:"hs256_secret" env:"AUTH_HS256_SECRET" secret:"true"`
+	// JWKSFile and JWKSURL provide the RS256 public keys; use one of them.
+	JWKSFile            string        `yaml:"jwks_file" env:"AUTH_JWKS_FILE"`
+	JWKSURL             string        `yaml:"jwks_url" env:"AUTH_JWKS_URL"`
+	JWKSRefreshInterval time.Duration `yaml:"jwks_refresh_interval" env:"AUTH_JWKS_REFRESH_INTERVAL"`
+	// Issuer and Audience, when set, must match the iss and aud claims.
+	Issuer   string `yaml:"issuer" env:"AUTH_ISSUER"`
+	Audience string `yaml:"audience" env:"AUTH_AUDIENCE"`
+	// APIKeys accepts keys from the api_keys table in the x-api-key metadata.
+	APIKeys bool `yaml:"api_keys" env:"AUTH_API_KEYS"`
+	// BootstrapAPIKey, when set, is stored at startup as an admin API key
+	// unless it already exists, to mint the first keys without a JWT.
+	BootstrapAPIKey string `yaml:"bootstrap_api_key" env:"AUTH_BOOTSTRAP_API_KEY" secret:"true"`
+	// PublicMethods are full gRPC method names callable without a token.
+	// "/package.Service/*" covers a whole service.
+	PublicMethods []string `yaml:"public_methods" env:"AUTH_PUBLIC_METHODS"`
+	// MethodRoles overrides the (pb.access) roles declared in the proto
+	// files, keyed by full gRPC method name. YAML only.
+	MethodRoles map[string][]string `yaml:"method_roles"`
+}
+
+// RateLimitConfig sets token bucket quotas: Burst requests at once,
+// refilled at RPS requests per second.
+type RateLimitConfig struct {
+	Enabled bool `yaml:"enabled" env:"RATE_LIMIT_ENABLED"`
+	// RPS and Burst are the quota of each client on each RPC without an
+	// entry in Methods.
+	RPS   float64 `yaml:"rps" env:"RATE_LIMIT_RPS"`
+	Burst int     `yaml:"burst" env:"RATE_LIMIT_BURST"`
+	// HTTPRPS and HTTPBurst are the quota of each client IP across all HTTP
+	// routes except the probes and /metrics.
+	HTTPRPS   float64 `yaml:"http_rps" env:"RATE_LIMIT_HTTP_RPS"`
+	HTTPBurst int     `yaml:"http_burst" env:"RATE_LIMIT_HTTP_BURST"`
+	// IPRPS and IPBurst are the quota of each client IP across all RPCs,
+	// checked before authentication.
+	IPRPS   float64 `yaml:"ip_rps" env:"RATE_LIMIT_IP_RPS"`
+	IPBurst int     `yaml:"ip_burst" env:"RATE_LIMIT_IP_BURST"`
+	// Methods are per-method quotas keyed by full gRPC method name. YAML only.
+	Methods map[string]RateLimitQuota `yaml:"methods"`
+	// CleanupInterval is how often idle clients are forgotten.
+	CleanupInterval time.Duration `yaml:"cleanup_interval" env:"RATE_LIMIT_CLEANUP_INTERVAL"`
+}
+
+type RateLimitQuota struct {
+	RPS   float64 `yaml:"rps"`
+	Burst int     `yaml:"burst"`
+}
+
+type StockConfig struct {
+	// ReservationSweepInterval is how often expired stock reservations are
+	// released.
+	ReservationSweepInterval time.Duration `yaml:"reservation_sweep_interval" env:"STOCK_RESERVATION_SWEEP_INTERVAL"`
+}
+
+// Default returns the configuration used when nothing is overridden.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			HTTPPort:            8080,
+			GRPCPort:            50051,
+			GRPCHost:            "localhost",
+			ShutdownTimeout:     15 * time.Second,
+			ReadHeaderTimeout:   10 * time.Second,
+			ReadTimeout:         30 * time.Second,
+			WriteTimeout:        30 * time.Second,
+			IdleTimeout:         120 * time.Second,
+			HealthCheckInterval: 10 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Host:            "localhost",
+			Port:            5432,
+			User:            "postgres",
+			Name:            "grpc_product",
+			SSLMode:         "require",
+			MaxOpenConns:    25,
+			MaxIdleConns:    25,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: []string{"*"},
+		},
+		Log: LogConfig{
+			Level: "info",
+		},
+		Auth: AuthConfig{
+			Enabled:             true,
+			APIKeys:             true,
+			JWKSRefreshInterval: 15 * time.Minute,
+			PublicMethods: []string{
+				"/pb.ProductService/GetProduct",
+				"/pb.ProductService/ListProducts",
+				"/pb.ProductService/SearchProducts",
+				"/pb.DipanTypeService/GetDipanType",
+				"/pb.DipanTypeService/ListDipanTypes",
+				"/grpc.health.v1.Health/*",
+			},
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:   true,
+			RPS:       50,
+			Burst:     100,
+			HTTPRPS:   100,
+			HTTPBurst: 200,
+			IPRPS:     100,
+			IPBurst:   200,
+			Methods: map[string]RateLimitQuota{
+				"/pb.ProductService/ListProducts":   {RPS: 10, Burst: 20},
+				"/pb.ProductService/SearchProducts": {RPS: 10, Burst: 20},
+			},
+			CleanupInterval: time.Minute,
+		},
+		Stock: StockConfig{
+			ReservationSweepInterval: 30 * time.Second,
+		},
+		Tracing: TracingConfig{
+			Exporter:     "none",
+			ServiceName:  "go-backend-service",
+			OTLPEndpoint: "localhost:4317",
+			SampleRatio:  1,
+		},
+	}
+}
+
+// Load builds the configuration from defaults, the YAML file at path (if
+// path is not empty) and environment variables, then validates it.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read config file: %w", err)
+		}
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(cfg); err != nil {
+			return nil, fmt.Errorf("parse config file %s: %w", path, err)
+		}
+	}
+
+	if err := applyEnv(reflect.ValueOf(cfg).Elem()); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate reports every invalid setting at once.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(validPort(c.Server.HTTPPort), "server.http_port: %d is not a valid port", c.Server.HTTPPort)
+	check(validPort(c.Server.GRPCPort), "server.grpc_port: %d is not a valid port", c.Server.GRPCPort)
+	check(c.Server.HTTPPort != c.Server.GRPCPort, "server.http_port and server.grpc_port must differ")
+	check(c.Server.GRPCHost != "", "server.grpc_host is required")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout must be positive")
+	check(c.Server.ReadHeaderTimeout >= 0, "server.read_header_timeout must not be negative")
+	check(c.Server.ReadTimeout >= 0, "server.read_timeout must not be negative")
+	check(c.Server.WriteTimeout >= 0, "server.write_timeout must not be negative")
+	check(c.Server.IdleTimeout >= 0, "server.idle_timeout must not be negative")
+	check(c.Server.HealthCheckInterval > 0, "server.health_check_interval must be positive")
+
+	check(c.Database.Host != "", "database.host is required (DB_HOST)")
+	check(validPort(c.Database.Port), "database.port: %d is not a valid port", c.Database.Port)
+	check(c.Database.User != "", "database.user is required (DB_USER)")
+	check(c.Database.Password != "", "database.password is required (DB_PASSWORD)")
+	check(c.Database.Name != "", "database.name is required (DB_NAME)")
+	switch c.Database.SSLMode {
+	case "disable", "require", "verify-ca", "verify-full":
+	default:
+		check(false, "database.sslmode: %q must be one of disable, require, verify-ca, verify-full", c.Database.SSLMode)
+	}
+	check(c.Database.MaxOpenConns >= 0, "database.max_open_conns must not be negative")
+	check(c.Database.MaxIdleConns >= 0, "database.max_idle_conns must not be negative")
+	check(c.Database.MaxOpenConns == 0 || c.Database.MaxIdleConns <= c.Database.MaxOpenConns,
+		"database.max_idle_conns must not exceed database.max_open_conns")
+	check(c.Database.ConnMaxLifetime >= 0, "database.conn_max_lifetime must not be negative")
+	check(c.Database.ConnMaxIdleTime >= 0, "database.conn_max_idle_time must not be negative")
+
+	if c.TLS.Enabled {
+		check(c.TLS.CertFile != "", "tls.cert_file is required when TLS is enabled")
+		check(c.TLS.KeyFile != "", "tls.key_file is required when TLS is enabled")
+	}
+
+	check(len(c.CORS.AllowedOrigins) > 0, "cors.allowed_origins must not be empty")
+
+	switch c.Log.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		check(false, "log.level: %q must be one of debug, info, warn, error", c.Log.Level)
+	}
+
+	switch c.Tracing.Exporter {
+	case "none", "otlp", "stdout":
+	default:
+		check(false, "tracing.exporter: %q must be one of none, otlp, stdout", c.Tracing.Exporter)
+	}
+	check(c.Tracing.ServiceName != "", "tracing.service_name is required")
+	check(c.Tracing.Exporter != "otlp" || c.Tracing.OTLPEndpoint != "", "tracing.otlp_endpoint is required with the otlp exporter")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio: %v must be between 0 and 1", c.Tracing.SampleRatio)
+
+	if c.Auth.Enabled {
+		check(c.Auth.HS256Secret != "" || c.Auth.JWKSFile != "" || c.Auth.JWKSURL != "" || c.Auth.APIKeys,
+			"auth: one of hs256_secret, jwks_file, jwks_url or api_keys is required when auth is enabled")
+		check(c.Auth.HS256Secret == "" || len(c.Auth.HS256Secret) >= 32, "auth.hs256_secret must be at least 32 bytes")
+		check(!placeholderSecret(c.Auth.HS256Secret), "auth.hs256_secret is the example placeholder; generate a random secret")
+		if c.Auth.BootstrapAPIKey != "" {
+			check(c.Auth.APIKeys, "auth.bootstrap_api_key requires auth.api_keys")
+			check(len(c.Auth.BootstrapAPIKey) >= 32, "auth.bootstrap_api_key must be at least 32 bytes")
+			check(!placeholderSecret(c.Auth.BootstrapAPIKey), "auth.bootstrap_api_key is the example placeholder; generate a random key")
+		}
+		check(c.Auth.JWKSFile == "" || c.Auth.JWKSURL == "", "auth.jwks_file and auth.jwks_url are mutually exclusive")
+		check(c.Auth.JWKSRefreshInterval > 0, "auth.jwks_refresh_interval must be positive")
+		for method, roles := range c.Auth.MethodRoles {
+			check(strings.HasPrefix(method, "/") && strings.Count(method, "/") == 2,
+				"auth.method_roles: %q is not a full method name (/package.Service/Method)", method)
+			check(len(roles) > 0, "auth.method_roles: %s needs at least one role", method)
+		}
+	}
+
+	if c.RateLimit.Enabled {
+		check(c.RateLimit.RPS > 0 && c.RateLimit.Burst > 0, "rate_limit.rps and rate_limit.burst must be positive")
+		check(c.RateLimit.HTTPRPS > 0 && c.RateLimit.HTTPBurst > 0, "rate_limit.http_rps and rate_limit.http_burst must be positive")
+		check(c.RateLimit.IPRPS > 0 && c.RateLimit.IPBurst > 0, "rate_limit.ip_rps and rate_limit.ip_burst must be positive")
+		for method, quota := range c.RateLimit.Methods {
+			check(strings.HasPrefix(method, "/") && strings.Count(method, "/") == 2,
+				"rate_limit.methods: %q is not a full method name (/package.Service/Method)", method)
+			check(quota.RPS > 0 && quota.Burst > 0, "rate_limit.methods: %s needs a positive rps and burst", method)
+		}
+		check(c.RateLimit.CleanupInterval > 0, "rate_limit.cleanup_interval must be positive")
+	}
+
+	check(c.Stock.ReservationSweepInterval > 0, "stock.reservation_sweep_interval must be positive")
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func validPort(port int) bool {
+	return port > 0 && port <= 65535
+}
+
+// placeholderSecret reports whether secret is one of the "change-me" values
+// shipped in the examples, which must never sign real tokens.
+func placeholderSecret(secret string) bool {
+	return strings.HasPrefix(strings.ToLower(secret), "change-me")
+}
+
+// applyEnv overrides every field tagged with `env` whose variable is set.
+func applyEnv(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			if err := applyEnv(field); err != nil {
+				return err
+			}
+			continue
+		}
+
+		key := t.Field(i).Tag.Get("env")
+		if key == "" {
+			continue
+		}
+		value, ok := os.LookupEnv(key)
+		if !ok || value == "" {
+			continue
+		}
+		if err := setField(field, value); err != nil {
+			return fmt.Errorf("invalid %s=%q: %w", key, value, err)
+		}
+	}
+	return nil
+}
+
+func setField(field reflect.Value, value string) error {
+	switch field.Interface().(type) {
+	case string:
+		field.SetString(value)
+	case int:
+		// Ports used to be given as ":8080"
+		n, err := strconv.Atoi(strings.TrimPrefix(value, ":"))
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(n))
+	case bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case time.Duration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+	case []string:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateRejectsPlaceholderSecret(t *testing.T) {
+	for _, secret := range []string{
+		"change-me-to-a-32-byte-or-longer-secret",
+		"CHANGE-ME-please-this-is-32-bytes-long",
+	} {
+		cfg := validConfig()
+		cfg.Auth.HS256Secret = secret
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "placeholder") {
+			t.Errorf("secret %q: err = %v, want the placeholder rejected", secret, err)
+		}
+	}
+
+	cfg := validConfig()
+	cfg.Auth.HS256Secret = "k3JxV9bq0Zr7uYt2WmN5sLp8aEc4HdFg"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("random secret: %v", err)
+	}
+}
+
+// validConfig is Default with the settings it leaves for the operator.
+func validConfig() *Config {
+	cfg := Default()
+	cfg.Database.Password = "passDblocal"
+	return cfg
+}
+
+func TestValidateBootstrapAPIKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		apiKeys bool
+		wantErr string
+	}{
+		{"random", "Zt4mQ8rW2yXc6vBn1kLp9sDf3gHj7aE5", true, ""},
+		{"short", "too-short", true, "at least 32 bytes"},
+		{"placeholder", "change-me-to-a-32-byte-or-longer-key", true, "placeholder"},
+		{"api keys off", "Zt4mQ8rW2yXc6vBn1kLp9sDf3gHj7aE5", false, "requires auth.api_keys"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			cfg.Auth.BootstrapAPIKey = tt.key
+			cfg.Auth.APIKeys = tt.apiKeys
+			cfg.Auth.HS256Secret = "k3JxV9bq0Zr7uYt2WmN5sLp8aEc4HdFg"
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
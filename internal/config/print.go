@@ -0,0 +1,61 @@
+package config
+
+import (
+	"io"
+	"reflect"
+	"time"
+
+	"go-backend-service/internal/redact"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Print writes the effective configuration as YAML. Fields tagged
+// `secret:"true"` are redacted.
+func (c *Config) Print(w io.Writer) error {
+	node, err := toNode(reflect.ValueOf(c).Elem())
+	if err != nil {
+		return err
+	}
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// toNode converts v into a YAML node keeping the struct field order.
+func toNode(v reflect.Value) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := &yaml.Node{Kind: yaml.ScalarNode, Value: field.Tag.Get("yaml")}
+
+		var value *yaml.Node
+		var err error
+		switch fv := v.Field(i); {
+		case fv.Kind() == reflect.Struct:
+			value, err = toNode(fv)
+		case field.Tag.Get("secret") == "true":
+			value = &yaml.Node{}
+			if fv.IsZero() {
+				err = value.Encode("")
+			} else {
+				err = value.Encode(redact.Placeholder)
+			}
+		case fv.Type() == reflect.TypeOf(time.Duration(0)):
+			value = &yaml.Node{}
+			err = value.Encode(fv.Interface().(time.Duration).String())
+		default:
+			value = &yaml.Node{}
+			err = value.Encode(fv.Interface())
+		}
+		if err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, key, value)
+	}
+	return node, nil
+}
@@ -0,0 +1,80 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"go-backend-service/internal/domain"
+	"go-backend-service/pkg/pb"
+)
+
+type APIKeyHandler struct {
+	pb.UnimplementedApiKeyServiceServer
+	apiKeyUsecase domain.APIKeyUsecase
+}
+
+func NewAPIKeyHandler(apiKeyUsecase domain.APIKeyUsecase) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyUsecase: apiKeyUsecase,
+	}
+}
+
+func (h *APIKeyHandler) CreateApiKey(ctx context.Context, req *pb.CreateApiKeyRequest) (*pb.CreateApiKeyResponse, error) {
+	apiKey := &domain.APIKey{Name: req.Name, Scopes: req.Scopes}
+	if req.ExpiresAt != "" {
+		expiresAt, err := time.Parse(time.RFC3339, req.ExpiresAt)
+		if err != nil {
+			return nil, domain.NewError(domain.ErrInvalidArgument, "INVALID_FIELD", "expires_at must be an RFC 3339 timestamp").
+				WithMetadata("field", "expires_at")
+		}
+		apiKey.ExpiresAt = &expiresAt
+	}
+
+	key, err := h.apiKeyUsecase.CreateAPIKey(ctx, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CreateApiKeyResponse{ApiKey: h.domainToProto(apiKey), Key: key}, nil
+}
+
+func (h *APIKeyHandler) ListApiKeys(ctx context.Context, req *pb.ListApiKeysRequest) (*pb.ListApiKeysResponse, error) {
+	apiKeys, err := h.apiKeyUsecase.ListAPIKeys(ctx, req.IncludeRevoked)
+	if err != nil {
+		return nil, err
+	}
+
+	var pbAPIKeys []*pb.ApiKey
+	for _, apiKey := range apiKeys {
+		pbAPIKeys = append(pbAPIKeys, h.domainToProto(apiKey))
+	}
+	return &pb.ListApiKeysResponse{ApiKeys: pbAPIKeys}, nil
+}
+
+func (h *APIKeyHandler) RevokeApiKey(ctx context.Context, req *pb.RevokeApiKeyRequest) (*pb.ApiKey, error) {
+	apiKey, err := h.apiKeyUsecase.RevokeAPIKey(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return h.domainToProto(apiKey), nil
+}
+
+func (h *APIKeyHandler) domainToProto(apiKey *domain.APIKey) *pb.ApiKey {
+	return &pb.ApiKey{
+		Id:         apiKey.ID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     apiKey.Scopes,
+		ExpiresAt:  optionalTime(apiKey.ExpiresAt),
+		LastUsedAt: optionalTime(apiKey.LastUsedAt),
+		RevokedAt:  optionalTime(apiKey.RevokedAt),
+		CreatedAt:  apiKey.CreatedAt.String(),
+	}
+}
+
+// optionalTime formats t like the other timestamps, or "" when t is nil.
+func optionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.String()
+}
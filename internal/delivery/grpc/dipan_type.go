@@ -2,53 +2,108 @@ package grpc
 
 import (
 	"context"
-	"go-backend-service/internal/usecase"
+
+	"go-backend-service/internal/domain"
 	"go-backend-service/pkg/pb"
 )
 
 type DipanTypeHandler struct {
 	pb.UnimplementedDipanTypeServiceServer
-	uc usecase.DipanTypeUsecase
+	dipanTypeUsecase domain.DipanTypeUsecase
 }
 
-func NewDipanTypeHandler(uc usecase.DipanTypeUsecase) *DipanTypeHandler {
-	return &DipanTypeHandler{uc: uc}
+func NewDipanTypeHandler(dipanTypeUsecase domain.DipanTypeUsecase) *DipanTypeHandler {
+	return &DipanTypeHandler{
+		dipanTypeUsecase: dipanTypeUsecase,
+	}
 }
 
 func (h *DipanTypeHandler) CreateDipanType(ctx context.Context, req *pb.CreateDipanTypeRequest) (*pb.DipanType, error) {
-	d := &pb.DipanType{NamaType: req.NamaType}
-	if err := h.uc.Create(ctx, d); err != nil {
+	dipanType := &domain.DipanType{NamaType: req.NamaType}
+	if err := h.dipanTypeUsecase.CreateDipanType(ctx, dipanType); err != nil {
 		return nil, err
 	}
-	return d, nil
+	setETag(ctx, dipanType.Version)
+	return h.domainToProto(dipanType), nil
 }
 
 func (h *DipanTypeHandler) GetDipanType(ctx context.Context, req *pb.GetDipanTypeRequest) (*pb.DipanType, error) {
-	return h.uc.GetByID(ctx, req.Id)
+	dipanType, err := h.dipanTypeUsecase.GetDipanType(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	setETag(ctx, dipanType.Version)
+	return h.domainToProto(dipanType), nil
 }
 
 func (h *DipanTypeHandler) ListDipanTypes(ctx context.Context, req *pb.ListDipanTypesRequest) (*pb.ListDipanTypesResponse, error) {
-	items, total, err := h.uc.List(ctx, req.Page, req.Limit)
+	dipanTypes, result, err := h.dipanTypeUsecase.ListDipanTypes(ctx, domain.ListQuery{
+		Filter:  req.Filter,
+		OrderBy: req.OrderBy,
+		Page: domain.PageRequest{
+			Page:         req.Page,
+			Limit:        req.Limit,
+			PageToken:    req.PageToken,
+			IncludeTotal: req.IncludeTotal,
+		},
+	})
 	if err != nil {
 		return nil, err
 	}
+
+	var pbDipanTypes []*pb.DipanType
+	for _, dipanType := range dipanTypes {
+		pbDipanTypes = append(pbDipanTypes, h.domainToProto(dipanType))
+	}
+
 	return &pb.ListDipanTypesResponse{
-		DipanTypes: items,
-		Total:      total,
+		DipanTypes:    pbDipanTypes,
+		Total:         result.Total,
+		NextPageToken: result.NextPageToken,
 	}, nil
 }
 
 func (h *DipanTypeHandler) UpdateDipanType(ctx context.Context, req *pb.UpdateDipanTypeRequest) (*pb.DipanType, error) {
-	d := &pb.DipanType{Id: req.Id, NamaType: req.NamaType}
-	if err := h.uc.Update(ctx, d); err != nil {
+	version, err := requestVersion(ctx, req.Etag, h.currentVersion(ctx, req.Id))
+	if err != nil {
+		return nil, err
+	}
+	dipanType := &domain.DipanType{ID: req.Id, NamaType: req.NamaType, Version: version}
+	if err := h.dipanTypeUsecase.UpdateDipanType(ctx, dipanType); err != nil {
 		return nil, err
 	}
-	return d, nil
+	setETag(ctx, dipanType.Version)
+	return h.domainToProto(dipanType), nil
 }
 
 func (h *DipanTypeHandler) DeleteDipanType(ctx context.Context, req *pb.DeleteDipanTypeRequest) (*pb.DeleteDipanTypeResponse, error) {
-	if err := h.uc.Delete(ctx, req.Id); err != nil {
+	version, err := requestVersion(ctx, req.Etag, h.currentVersion(ctx, req.Id))
+	if err != nil {
 		return nil, err
 	}
-	return &pb.DeleteDipanTypeResponse{}, nil
+	if err := h.dipanTypeUsecase.DeleteDipanType(ctx, req.Id, version); err != nil {
+		return nil, err
+	}
+	return &pb.DeleteDipanTypeResponse{Success: true}, nil
+}
+
+// currentVersion reads the version of dipan type id, for If-Match lists.
+func (h *DipanTypeHandler) currentVersion(ctx context.Context, id int32) func() (int64, error) {
+	return func() (int64, error) {
+		dipanType, err := h.dipanTypeUsecase.GetDipanType(ctx, id)
+		if err != nil {
+			return 0, err
+		}
+		return dipanType.Version, nil
+	}
+}
+
+func (h *DipanTypeHandler) domainToProto(dipanType *domain.DipanType) *pb.DipanType {
+	return &pb.DipanType{
+		Id:        dipanType.ID,
+		NamaType:  dipanType.NamaType,
+		CreatedAt: dipanType.CreatedAt.String(),
+		UpdatedAt: dipanType.UpdatedAt.String(),
+		Etag:      etag(dipanType.Version),
+	}
 }
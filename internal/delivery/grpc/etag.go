@@ -0,0 +1,89 @@
+package grpc
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go-backend-service/internal/domain"
+
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// IfMatchMetadataKey carries the If-Match header of REST calls, which
+	// the gateway forwards. It applies when a request has no etag field.
+	IfMatchMetadataKey = "if-match"
+	// ETagMetadataKey carries the etag of the returned entity, which the
+	// gateway returns as the ETag header.
+	ETagMetadataKey = "etag"
+)
+
+// etag formats version as a strong HTTP entity tag, e.g. "3" with quotes.
+func etag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// requestVersion returns the version a write is conditional on, named by
+// the etag field of a request or else by If-Match. It is 0, meaning any
+// version, when neither is set or If-Match is "*". If-Match may list
+// several etags; current is then called to pick the listed version the
+// entity is at. Weak etags (W/"3") never match, as If-Match compares
+// strongly, so a precondition made only of them fails with
+// domain.ErrVersionMismatch.
+func requestVersion(ctx context.Context, etagField string, current func() (int64, error)) (int64, error) {
+	value := strings.TrimSpace(etagField)
+	if value == "" {
+		value = strings.Join(metadata.ValueFromIncomingContext(ctx, IfMatchMetadataKey), ",")
+	}
+
+	var versions []int64
+	listed := false
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.TrimSpace(tag)
+		switch {
+		case tag == "":
+			continue
+		case tag == "*":
+			return 0, nil
+		case strings.HasPrefix(tag, "W/"):
+			listed = true
+			continue
+		}
+		listed = true
+		if unquoted, err := strconv.Unquote(tag); err == nil {
+			tag = unquoted
+		}
+		version, err := strconv.ParseInt(tag, 10, 64)
+		if err != nil || version <= 0 {
+			return 0, domain.NewError(domain.ErrInvalidArgument, "INVALID_FIELD", "malformed etag").
+				WithMetadata("field", "etag")
+		}
+		versions = append(versions, version)
+	}
+
+	switch {
+	case !listed:
+		return 0, nil
+	case len(versions) == 0:
+		return 0, domain.ErrVersionMismatch
+	case len(versions) == 1:
+		return versions[0], nil
+	}
+	// The write still checks the version, in case it changes meanwhile.
+	version, err := current()
+	if err != nil {
+		return 0, err
+	}
+	if !slices.Contains(versions, version) {
+		return 0, domain.ErrVersionMismatch.WithMetadata("current_version", strconv.FormatInt(version, 10))
+	}
+	return version, nil
+}
+
+// setETag returns the etag of version to the caller as response metadata.
+func setETag(ctx context.Context, version int64) {
+	grpclib.SetHeader(ctx, metadata.Pairs(ETagMetadataKey, etag(version)))
+}
@@ -0,0 +1,89 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-backend-service/internal/domain"
+
+	"google.golang.org/grpc/metadata"
+)
+
+func TestRequestVersion(t *testing.T) {
+	malformed := domain.NewError(domain.ErrInvalidArgument, "INVALID_FIELD", "")
+	tests := []struct {
+		name    string
+		field   string
+		ifMatch []string
+		current int64
+		want    int64
+		wantErr error
+	}{
+		{name: "unconditional"},
+		{name: "field quoted", field: `"3"`, want: 3},
+		{name: "field unquoted", field: "3", want: 3},
+		{name: "field star", field: "*"},
+		{name: "field weak", field: `W/"3"`, current: 3, wantErr: domain.ErrVersionMismatch},
+		{name: "field malformed", field: "three", wantErr: malformed},
+		{name: "if-match quoted", ifMatch: []string{`"3"`}, want: 3},
+		{name: "if-match unquoted", ifMatch: []string{"3"}, want: 3},
+		{name: "if-match star", ifMatch: []string{"*"}},
+		{name: "if-match weak", ifMatch: []string{`W/"3"`}, current: 3, wantErr: domain.ErrVersionMismatch},
+		{name: "if-match weak list", ifMatch: []string{`W/"3", W/"4"`}, current: 3, wantErr: domain.ErrVersionMismatch},
+		{name: "if-match weak and strong", ifMatch: []string{`W/"4", "3"`}, want: 3},
+		{name: "if-match list", ifMatch: []string{`"3", "4"`}, current: 4, want: 4},
+		{name: "if-match list without spaces", ifMatch: []string{`"3","4"`}, current: 3, want: 3},
+		{name: "if-match list not matching", ifMatch: []string{`"3", "4"`}, current: 5, wantErr: domain.ErrVersionMismatch},
+		{name: "if-match headers", ifMatch: []string{`"3"`, `"4"`}, current: 4, want: 4},
+		{name: "if-match trailing comma", ifMatch: []string{`"3",`}, want: 3},
+		{name: "if-match zero", ifMatch: []string{`"0"`}, wantErr: malformed},
+		{name: "if-match malformed", ifMatch: []string{`"abc"`}, wantErr: malformed},
+		{name: "if-match malformed in list", ifMatch: []string{`"3", abc`}, wantErr: malformed},
+		{name: "field wins over if-match", field: `"2"`, ifMatch: []string{`"3"`}, want: 2},
+		{name: "empty field uses if-match", field: " ", ifMatch: []string{`"3"`}, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			for _, value := range tt.ifMatch {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Join(mdFrom(ctx), metadata.Pairs(IfMatchMetadataKey, value)))
+			}
+			lookups := 0
+			current := func() (int64, error) {
+				lookups++
+				return tt.current, nil
+			}
+
+			got, err := requestVersion(ctx, tt.field, current)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("version = %d, want %d", got, tt.want)
+			}
+			if tt.current == 0 && lookups > 0 {
+				t.Errorf("looked up the current version for a single etag")
+			}
+		})
+	}
+}
+
+func TestRequestVersionLookupError(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(IfMatchMetadataKey, `"3", "4"`))
+	_, err := requestVersion(ctx, "", func() (int64, error) { return 0, domain.ErrProductNotFound })
+	if !errors.Is(err, domain.ErrProductNotFound) {
+		t.Errorf("err = %v, want ErrProductNotFound", err)
+	}
+}
+
+func mdFrom(ctx context.Context) metadata.MD {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return md
+}
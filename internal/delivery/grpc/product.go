@@ -2,14 +2,31 @@ package grpc
 
 import (
 	"context"
+	"time"
 
+	"go-backend-service/internal/auth"
 	"go-backend-service/internal/domain"
 	"go-backend-service/pkg/pb"
-
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
+// anonymousActor is recorded as the actor of changes made while
+// authentication is disabled.
+const anonymousActor = "anonymous"
+
+var reservationStatuses = map[domain.ReservationStatus]pb.StockReservationStatus{
+	domain.ReservationActive:    pb.StockReservationStatus_STOCK_RESERVATION_STATUS_ACTIVE,
+	domain.ReservationCommitted: pb.StockReservationStatus_STOCK_RESERVATION_STATUS_COMMITTED,
+	domain.ReservationReleased:  pb.StockReservationStatus_STOCK_RESERVATION_STATUS_RELEASED,
+	domain.ReservationExpired:   pb.StockReservationStatus_STOCK_RESERVATION_STATUS_EXPIRED,
+}
+
+var stockMovementTypes = map[pb.StockMovementType]domain.StockMovementType{
+	pb.StockMovementType_STOCK_MOVEMENT_TYPE_RECEIVE:    domain.StockReceive,
+	pb.StockMovementType_STOCK_MOVEMENT_TYPE_SELL:       domain.StockSell,
+	pb.StockMovementType_STOCK_MOVEMENT_TYPE_RETURN:     domain.StockReturn,
+	pb.StockMovementType_STOCK_MOVEMENT_TYPE_CORRECTION: domain.StockCorrection,
+}
+
 type ProductHandler struct {
 	pb.UnimplementedProductServiceServer
 	productUsecase domain.ProductUsecase
@@ -31,58 +48,265 @@ func (h *ProductHandler) CreateProduct(ctx context.Context, req *pb.CreateProduc
 
 	err := h.productUsecase.CreateProduct(ctx, product)
 	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, err
 	}
 
-	return &pb.Product{
-		Id:          product.ID,
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-		Stock:       product.Stock,
-		CreatedAt:   product.CreatedAt.String(),
-		UpdatedAt:   product.UpdatedAt.String(),
-	}, nil
+	setETag(ctx, product.Version)
+	return h.domainToProto(product), nil
 }
 
 func (h *ProductHandler) GetProduct(ctx context.Context, req *pb.GetProductRequest) (*pb.Product, error) {
 	product, err := h.productUsecase.GetProduct(ctx, req.Id)
 	if err != nil {
-		return nil, status.Error(codes.NotFound, err.Error())
+		return nil, err
 	}
 
-	return &pb.Product{
-		Id:          product.ID,
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-		Stock:       product.Stock,
-		CreatedAt:   product.CreatedAt.String(),
-		UpdatedAt:   product.UpdatedAt.String(),
-	}, nil
+	setETag(ctx, product.Version)
+	return h.domainToProto(product), nil
 }
 
 func (h *ProductHandler) ListProducts(ctx context.Context, req *pb.ListProductsRequest) (*pb.ListProductsResponse, error) {
-	products, total, err := h.productUsecase.ListProducts(ctx, req.Page, req.Limit)
+	products, result, err := h.productUsecase.ListProducts(ctx, domain.ListQuery{
+		Filter:  req.Filter,
+		OrderBy: req.OrderBy,
+		Page: domain.PageRequest{
+			Page:         req.Page,
+			Limit:        req.Limit,
+			PageToken:    req.PageToken,
+			IncludeTotal: req.IncludeTotal,
+		},
+	})
 	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, err
 	}
 
 	var pbProducts []*pb.Product
 	for _, product := range products {
-		pbProducts = append(pbProducts, &pb.Product{
-			Id:          product.ID,
-			Name:        product.Name,
-			Description: product.Description,
-			Price:       product.Price,
-			Stock:       product.Stock,
-			CreatedAt:   product.CreatedAt.String(),
-			UpdatedAt:   product.UpdatedAt.String(),
-		})
+		pbProducts = append(pbProducts, h.domainToProto(product))
 	}
 
 	return &pb.ListProductsResponse{
-		Products: pbProducts,
-		Total:    total,
+		Products:      pbProducts,
+		Total:         result.Total,
+		NextPageToken: result.NextPageToken,
+	}, nil
+}
+
+func (h *ProductHandler) SearchProducts(ctx context.Context, req *pb.SearchProductsRequest) (*pb.SearchProductsResponse, error) {
+	results, result, err := h.productUsecase.SearchProducts(ctx, req.Q, domain.PageRequest{
+		Limit:        req.Limit,
+		PageToken:    req.PageToken,
+		IncludeTotal: req.IncludeTotal,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var pbResults []*pb.ProductSearchResult
+	for _, res := range results {
+		pbResults = append(pbResults, &pb.ProductSearchResult{
+			Product: h.domainToProto(res.Product),
+			Rank:    res.Rank,
+			Snippet: res.Snippet,
+		})
+	}
+
+	return &pb.SearchProductsResponse{
+		Results:       pbResults,
+		Total:         result.Total,
+		NextPageToken: result.NextPageToken,
+	}, nil
+}
+
+func (h *ProductHandler) UpdateProduct(ctx context.Context, req *pb.UpdateProductRequest) (*pb.Product, error) {
+	version, err := requestVersion(ctx, req.Etag, h.currentVersion(ctx, req.Id))
+	if err != nil {
+		return nil, err
+	}
+	product := &domain.Product{
+		ID:          req.Id,
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       req.Price,
+		Version:     version,
+	}
+
+	err = h.productUsecase.UpdateProduct(ctx, product, req.GetUpdateMask().GetPaths())
+	if err != nil {
+		return nil, err
+	}
+
+	setETag(ctx, product.Version)
+	return h.domainToProto(product), nil
+}
+
+func (h *ProductHandler) DeleteProduct(ctx context.Context, req *pb.DeleteProductRequest) (*pb.DeleteProductResponse, error) {
+	version, err := requestVersion(ctx, req.Etag, h.currentVersion(ctx, req.Id))
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.productUsecase.DeleteProduct(ctx, req.Id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.DeleteProductResponse{Success: true}, nil
+}
+
+func (h *ProductHandler) AdjustStock(ctx context.Context, req *pb.AdjustStockRequest) (*pb.AdjustStockResponse, error) {
+	movement := &domain.StockMovement{
+		ProductID: req.ProductId,
+		Type:      stockMovementTypes[req.Type],
+		Quantity:  req.Quantity,
+		Reason:    req.Reason,
+		Actor:     actor(ctx),
+		Reference: req.Reference,
+	}
+
+	product, err := h.productUsecase.AdjustStock(ctx, movement)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.AdjustStockResponse{
+		Product:  h.domainToProto(product),
+		Movement: stockMovementToProto(movement),
+	}, nil
+}
+
+func (h *ProductHandler) ListStockMovements(ctx context.Context, req *pb.ListStockMovementsRequest) (*pb.ListStockMovementsResponse, error) {
+	movements, result, err := h.productUsecase.ListStockMovements(ctx, req.ProductId, domain.ListQuery{
+		Filter: req.Filter,
+		Page: domain.PageRequest{
+			Limit:        req.Limit,
+			PageToken:    req.PageToken,
+			IncludeTotal: req.IncludeTotal,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var pbMovements []*pb.StockMovement
+	for _, movement := range movements {
+		pbMovements = append(pbMovements, stockMovementToProto(movement))
+	}
+
+	return &pb.ListStockMovementsResponse{
+		Movements:     pbMovements,
+		Total:         result.Total,
+		NextPageToken: result.NextPageToken,
+	}, nil
+}
+
+func (h *ProductHandler) ReserveStock(ctx context.Context, req *pb.ReserveStockRequest) (*pb.ReserveStockResponse, error) {
+	reservation := &domain.StockReservation{
+		ProductID: req.ProductId,
+		Quantity:  req.Quantity,
+		Reference: req.Reference,
+		Actor:     actor(ctx),
+	}
+
+	product, err := h.productUsecase.ReserveStock(ctx, reservation, time.Duration(req.TtlSeconds)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ReserveStockResponse{
+		Reservation: reservationToProto(reservation),
+		Product:     h.domainToProto(product),
+	}, nil
+}
+
+func (h *ProductHandler) CommitReservation(ctx context.Context, req *pb.CommitReservationRequest) (*pb.CommitReservationResponse, error) {
+	reservation, product, err := h.productUsecase.CommitReservation(ctx, req.Id, actor(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CommitReservationResponse{
+		Reservation: reservationToProto(reservation),
+		Product:     h.domainToProto(product),
 	}, nil
 }
+
+func (h *ProductHandler) ReleaseReservation(ctx context.Context, req *pb.ReleaseReservationRequest) (*pb.ReleaseReservationResponse, error) {
+	reservation, product, err := h.productUsecase.ReleaseReservation(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ReleaseReservationResponse{
+		Reservation: reservationToProto(reservation),
+		Product:     h.domainToProto(product),
+	}, nil
+}
+
+// currentVersion reads the version of product id, for If-Match lists.
+func (h *ProductHandler) currentVersion(ctx context.Context, id string) func() (int64, error) {
+	return func() (int64, error) {
+		product, err := h.productUsecase.GetProduct(ctx, id)
+		if err != nil {
+			return 0, err
+		}
+		return product.Version, nil
+	}
+}
+
+func (h *ProductHandler) domainToProto(product *domain.Product) *pb.Product {
+	return &pb.Product{
+		Id:             product.ID,
+		Name:           product.Name,
+		Description:    product.Description,
+		Price:          product.Price,
+		Stock:          product.Stock,
+		ReservedStock:  product.Reserved,
+		AvailableStock: product.Available(),
+		CreatedAt:      product.CreatedAt.String(),
+		UpdatedAt:      product.UpdatedAt.String(),
+		Etag:           etag(product.Version),
+	}
+}
+
+func stockMovementToProto(movement *domain.StockMovement) *pb.StockMovement {
+	movementType := pb.StockMovementType_STOCK_MOVEMENT_TYPE_UNSPECIFIED
+	for pbType, domainType := range stockMovementTypes {
+		if domainType == movement.Type {
+			movementType = pbType
+		}
+	}
+	return &pb.StockMovement{
+		Id:         movement.ID,
+		ProductId:  movement.ProductID,
+		Type:       movementType,
+		Quantity:   movement.Quantity,
+		StockAfter: movement.StockAfter,
+		Reason:     movement.Reason,
+		Actor:      movement.Actor,
+		Reference:  movement.Reference,
+		CreatedAt:  movement.CreatedAt.String(),
+	}
+}
+
+func reservationToProto(reservation *domain.StockReservation) *pb.StockReservation {
+	return &pb.StockReservation{
+		Id:        reservation.ID,
+		ProductId: reservation.ProductID,
+		Quantity:  reservation.Quantity,
+		Status:    reservationStatuses[reservation.Status],
+		Reference: reservation.Reference,
+		Actor:     reservation.Actor,
+		ExpiresAt: reservation.ExpiresAt.String(),
+		CreatedAt: reservation.CreatedAt.String(),
+		UpdatedAt: reservation.UpdatedAt.String(),
+	}
+}
+
+// actor returns the subject of the caller, recorded with the changes it makes.
+func actor(ctx context.Context) string {
+	if claims, ok := auth.FromContext(ctx); ok && claims.Subject != "" {
+		return claims.Subject
+	}
+	return anonymousActor
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+var (
+	ErrAPIKeyNotFound = NewError(ErrNotFound, "API_KEY_NOT_FOUND", "api key not found")
+	ErrInvalidAPIKey  = NewError(ErrUnauthenticated, "INVALID_API_KEY", "invalid, expired or revoked api key")
+)
+
+// APIKey identifies a service-to-service caller. Scopes act as the caller's
+// roles. Only the SHA-256 hash of the key is stored.
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Active reports whether the key may be used at now.
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, apiKey *APIKey, keyHash string) error
+	GetByHash(ctx context.Context, keyHash string) (*APIKey, error)
+	List(ctx context.Context, includeRevoked bool) ([]*APIKey, error)
+	Revoke(ctx context.Context, id string) (*APIKey, error)
+	TouchLastUsed(ctx context.Context, id string, at time.Time) error
+}
+
+type APIKeyUsecase interface {
+	// CreateAPIKey stores apiKey and returns the generated key, which is
+	// not retrievable afterwards.
+	CreateAPIKey(ctx context.Context, apiKey *APIKey) (string, error)
+	ListAPIKeys(ctx context.Context, includeRevoked bool) ([]*APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string) (*APIKey, error)
+	// VerifyAPIKey returns the active key matching key, or ErrInvalidAPIKey.
+	VerifyAPIKey(ctx context.Context, key string) (*APIKey, error)
+	// EnsureAPIKey stores apiKey for the caller-chosen key unless that key
+	// was stored before, revoked or not, and reports whether it stored it.
+	EnsureAPIKey(ctx context.Context, apiKey *APIKey, key string) (bool, error)
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+var ErrDipanTypeNotFound = NewError(ErrNotFound, "DIPAN_TYPE_NOT_FOUND", "dipan type not found")
+
+// DipanType is a bed frame type. Version is incremented on every write and
+// exposed as the etag.
+type DipanType struct {
+	ID        int32     `json:"id"`
+	NamaType  string    `json:"nama_type"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Version   int64     `json:"version"`
+}
+
+type DipanTypeRepository interface {
+	Create(ctx context.Context, dipanType *DipanType) error
+	GetByID(ctx context.Context, id int32) (*DipanType, error)
+	List(ctx context.Context, query ListQuery) ([]*DipanType, PageResult, error)
+	// Update overwrites the dipan type if it is at dipanType.Version, or at
+	// any version when it is 0, and sets dipanType.Version to the new version.
+	Update(ctx context.Context, dipanType *DipanType) error
+	// Delete deletes the dipan type if it is at version, or at any version
+	// when version is 0.
+	Delete(ctx context.Context, id int32, version int64) error
+}
+
+type DipanTypeUsecase interface {
+	CreateDipanType(ctx context.Context, dipanType *DipanType) error
+	GetDipanType(ctx context.Context, id int32) (*DipanType, error)
+	ListDipanTypes(ctx context.Context, query ListQuery) ([]*DipanType, PageResult, error)
+	UpdateDipanType(ctx context.Context, dipanType *DipanType) error
+	DeleteDipanType(ctx context.Context, id int32, version int64) error
+}
@@ -0,0 +1,61 @@
+package domain
+
+import "errors"
+
+// Error kinds. Every domain error wraps one of these so the delivery layer
+// can pick the matching gRPC code and HTTP status without knowing about
+// individual entities.
+var (
+	ErrNotFound           = errors.New("not found")
+	ErrConflict           = errors.New("conflict")
+	ErrInvalidArgument    = errors.New("invalid argument")
+	ErrFailedPrecondition = errors.New("failed precondition")
+	ErrUnauthenticated    = errors.New("unauthenticated")
+	ErrPermissionDenied   = errors.New("permission denied")
+	ErrAborted            = errors.New("aborted")
+)
+
+// ErrVersionMismatch is returned by a write that names a version (etag) of
+// an entity that is no longer its current one.
+var ErrVersionMismatch = NewError(ErrAborted, "ETAG_MISMATCH", "etag does not match the current version")
+
+// Error is a domain error with a machine-readable reason, e.g.
+// PRODUCT_NOT_FOUND. Kind is one of the error kinds above.
+type Error struct {
+	Kind     error
+	Reason   string
+	Message  string
+	Metadata map[string]string
+}
+
+func NewError(kind error, reason, message string) *Error {
+	return &Error{Kind: kind, Reason: reason, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// Is reports whether target is a domain error with the same reason, so a copy
+// returned by WithMetadata still matches its sentinel.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Reason == e.Reason
+}
+
+// WithMetadata returns a copy of e with key set to value in its metadata.
+func (e *Error) WithMetadata(key, value string) *Error {
+	metadata := make(map[string]string, len(e.Metadata)+1)
+	for k, v := range e.Metadata {
+		metadata[k] = v
+	}
+	metadata[key] = value
+
+	err := *e
+	err.Metadata = metadata
+	return &err
+}
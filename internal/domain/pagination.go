@@ -0,0 +1,57 @@
+package domain
+
+const (
+	DefaultPageSize = 10
+	MaxPageSize     = 100
+)
+
+var ErrInvalidPageToken = NewError(ErrInvalidArgument, "INVALID_PAGE_TOKEN", "invalid page token")
+
+// PageRequest selects a page of a list. PageToken continues after the last
+// item of a previous page; Page is the older 1-based page number, kept for
+// compatibility. Total is only counted when IncludeTotal is set.
+type PageRequest struct {
+	Page         int32
+	Limit        int32
+	PageToken    string
+	IncludeTotal bool
+}
+
+// Normalize applies the default page size and caps it at MaxPageSize.
+func (p PageRequest) Normalize() (PageRequest, error) {
+	if p.Limit <= 0 {
+		p.Limit = DefaultPageSize
+	}
+	if p.Limit > MaxPageSize {
+		p.Limit = MaxPageSize
+	}
+	if p.Page <= 0 {
+		p.Page = 1
+	}
+	if p.Page > 1 && p.PageToken != "" {
+		return p, NewError(ErrInvalidArgument, "INVALID_FIELD", "page and page_token are mutually exclusive").
+			WithMetadata("field", "page")
+	}
+	return p, nil
+}
+
+// Offset is the number of items skipped by Page.
+func (p PageRequest) Offset() int64 {
+	return int64(p.Page-1) * int64(p.Limit)
+}
+
+// ListQuery selects, orders and pages a list. Filter is an AIP-160
+// expression such as `price >= 10 AND name:"meja"`, OrderBy an AIP-132 list
+// such as "price desc, name". Which fields they accept depends on the list.
+type ListQuery struct {
+	Filter  string
+	OrderBy string
+	Page    PageRequest
+}
+
+// PageResult describes the page returned for a PageRequest. NextPageToken is
+// empty on the last page. Total is only set when it was requested.
+type PageResult struct {
+	NextPageToken string
+	Total         int32
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPageRequestNormalize(t *testing.T) {
+	tests := []struct {
+		in   PageRequest
+		want PageRequest
+	}{
+		{PageRequest{}, PageRequest{Page: 1, Limit: DefaultPageSize}},
+		{PageRequest{Limit: -5}, PageRequest{Page: 1, Limit: DefaultPageSize}},
+		{PageRequest{Limit: 1}, PageRequest{Page: 1, Limit: 1}},
+		{PageRequest{Limit: MaxPageSize}, PageRequest{Page: 1, Limit: MaxPageSize}},
+		{PageRequest{Limit: MaxPageSize + 1}, PageRequest{Page: 1, Limit: MaxPageSize}},
+		{PageRequest{Page: -1, Limit: 20}, PageRequest{Page: 1, Limit: 20}},
+		{PageRequest{Page: 3, Limit: 20}, PageRequest{Page: 3, Limit: 20}},
+		{PageRequest{Page: 1, PageToken: "t"}, PageRequest{Page: 1, Limit: DefaultPageSize, PageToken: "t"}},
+	}
+	for _, tt := range tests {
+		got, err := tt.in.Normalize()
+		if err != nil {
+			t.Errorf("%+v: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%+v normalized to %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPageRequestNormalizeRejectsPageWithToken(t *testing.T) {
+	_, err := PageRequest{Page: 2, PageToken: "t"}.Normalize()
+	var domainErr *Error
+	if !errors.Is(err, ErrInvalidArgument) || !errors.As(err, &domainErr) || domainErr.Metadata["field"] != "page" {
+		t.Errorf("err = %v, want INVALID_FIELD on page", err)
+	}
+}
+
+func TestPageRequestOffset(t *testing.T) {
+	if got := (PageRequest{Page: 3, Limit: 20}).Offset(); got != 40 {
+		t.Errorf("Offset() = %d, want 40", got)
+	}
+	// Large pages must not overflow int32.
+	if got := (PageRequest{Page: 1 << 30, Limit: MaxPageSize}).Offset(); got != int64(1<<30-1)*MaxPageSize {
+		t.Errorf("Offset() = %d", got)
+	}
+}
@@ -5,24 +5,79 @@ import (
 	"time"
 )
 
+var ErrProductNotFound = NewError(ErrNotFound, "PRODUCT_NOT_FOUND", "product not found")
+
+// Product is a catalog item. Stock is the on-hand stock, of which Reserved
+// is held by active reservations. Version is incremented on every write and
+// exposed as the etag.
 type Product struct {
 	ID          string    `json:"id"`
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
 	Price       float64   `json:"price"`
 	Stock       int32     `json:"stock"`
+	Reserved    int32     `json:"reserved"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	Version     int64     `json:"version"`
+}
+
+// ProductSearchResult is a product matching a search. Rank is its relevance,
+// higher first, and Snippet its name and description with the matched words
+// wrapped in <mark></mark>.
+type ProductSearchResult struct {
+	Product *Product
+	Rank    float64
+	Snippet string
+}
+
+// Available is the stock that can still be reserved or sold.
+func (p *Product) Available() int32 {
+	return p.Stock - p.Reserved
 }
 
 type ProductRepository interface {
 	Create(ctx context.Context, product *Product) error
 	GetByID(ctx context.Context, id string) (*Product, error)
-	List(ctx context.Context, page, limit int32) ([]*Product, int32, error)
+	List(ctx context.Context, query ListQuery) ([]*Product, PageResult, error)
+	Search(ctx context.Context, text string, page PageRequest) ([]*ProductSearchResult, PageResult, error)
+	// Update overwrites the name, description and price of the product if it
+	// is still at product.Version, and sets product.Version to the new
+	// version. Stock is left to StockRepository.
+	Update(ctx context.Context, product *Product) error
+	// Delete deletes the product if it is at version, or at any version when
+	// version is 0.
+	Delete(ctx context.Context, id string, version int64) error
 }
 
 type ProductUsecase interface {
 	CreateProduct(ctx context.Context, product *Product) error
 	GetProduct(ctx context.Context, id string) (*Product, error)
-	ListProducts(ctx context.Context, page, limit int32) ([]*Product, int32, error)
+	ListProducts(ctx context.Context, query ListQuery) ([]*Product, PageResult, error)
+	// SearchProducts returns the products whose name or description match
+	// text, most relevant first. text uses web search syntax: words,
+	// "quoted phrases", or, and -excluded words.
+	SearchProducts(ctx context.Context, text string, page PageRequest) ([]*ProductSearchResult, PageResult, error)
+	// UpdateProduct applies the given fields of product (name, description,
+	// price) to the stored product. An empty fields list replaces all of
+	// them. A non-zero product.Version must match the stored version. On
+	// success product holds the full updated entity.
+	UpdateProduct(ctx context.Context, product *Product, fields []string) error
+	// DeleteProduct deletes a product at version, or at any version when
+	// version is 0.
+	DeleteProduct(ctx context.Context, id string, version int64) error
+	// AdjustStock applies movement to the stock of movement.ProductID and
+	// returns the updated product. Quantity is a positive amount for receive,
+	// sell and return, and the signed change for a correction.
+	AdjustStock(ctx context.Context, movement *StockMovement) (*Product, error)
+	ListStockMovements(ctx context.Context, productID string, query ListQuery) ([]*StockMovement, PageResult, error)
+	// ReserveStock holds reservation.Quantity units of the available stock of
+	// reservation.ProductID for ttl, or a default duration when ttl is zero.
+	ReserveStock(ctx context.Context, reservation *StockReservation, ttl time.Duration) (*Product, error)
+	// CommitReservation turns an active reservation into a sale, recorded as
+	// a sell movement made by actor.
+	CommitReservation(ctx context.Context, id, actor string) (*StockReservation, *Product, error)
+	// ReleaseReservation returns the stock of a reservation. Releasing a
+	// reservation that was already released or expired is a no-op.
+	ReleaseReservation(ctx context.Context, id string) (*StockReservation, *Product, error)
 }
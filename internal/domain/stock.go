@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+var (
+	ErrInsufficientStock    = NewError(ErrFailedPrecondition, "INSUFFICIENT_STOCK", "insufficient stock")
+	ErrReservationNotFound  = NewError(ErrNotFound, "RESERVATION_NOT_FOUND", "reservation not found")
+	ErrReservationNotActive = NewError(ErrFailedPrecondition, "RESERVATION_NOT_ACTIVE", "reservation is not active")
+	ErrReservationExpired   = NewError(ErrFailedPrecondition, "RESERVATION_EXPIRED", "reservation has expired")
+)
+
+// StockMovementType is why the stock of a product changed.
+type StockMovementType string
+
+const (
+	// StockReceive adds goods received from a supplier.
+	StockReceive StockMovementType = "receive"
+	// StockSell removes sold goods.
+	StockSell StockMovementType = "sell"
+	// StockReturn adds goods returned by a customer.
+	StockReturn StockMovementType = "return"
+	// StockCorrection fixes the stock after a count, in either direction.
+	StockCorrection StockMovementType = "correction"
+)
+
+// StockMovement is an entry of the append-only stock ledger. Quantity is the
+// signed change of the product stock, StockAfter the resulting stock and
+// Actor the subject of the caller who made the change.
+type StockMovement struct {
+	ID         string            `json:"id"`
+	ProductID  string            `json:"product_id"`
+	Type       StockMovementType `json:"type"`
+	Quantity   int32             `json:"quantity"`
+	StockAfter int32             `json:"stock_after"`
+	Reason     string            `json:"reason"`
+	Actor      string            `json:"actor"`
+	Reference  string            `json:"reference"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+// ReservationStatus is the state of a stock reservation. Only active
+// reservations hold stock.
+type ReservationStatus string
+
+const (
+	ReservationActive    ReservationStatus = "active"
+	ReservationCommitted ReservationStatus = "committed"
+	ReservationReleased  ReservationStatus = "released"
+	ReservationExpired   ReservationStatus = "expired"
+)
+
+// StockReservation holds Quantity units of a product, e.g. while a checkout
+// waits for payment, until it is committed, released or expires.
+type StockReservation struct {
+	ID        string            `json:"id"`
+	ProductID string            `json:"product_id"`
+	Quantity  int32             `json:"quantity"`
+	Status    ReservationStatus `json:"status"`
+	Reference string            `json:"reference"`
+	Actor     string            `json:"actor"`
+	ExpiresAt time.Time         `json:"expires_at"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+type StockRepository interface {
+	// Apply adds movement.Quantity to the stock of the product and records
+	// movement in the same transaction, setting movement.StockAfter. It fails
+	// with ErrInsufficientStock if the stock would go negative.
+	Apply(ctx context.Context, movement *StockMovement) (*Product, error)
+	// ListMovements fails with ErrProductNotFound when the product does not
+	// exist.
+	ListMovements(ctx context.Context, productID string, query ListQuery) ([]*StockMovement, PageResult, error)
+	// Reserve adds reservation.Quantity to the reserved stock of the product
+	// and records reservation. It fails with ErrInsufficientStock when less
+	// is available.
+	Reserve(ctx context.Context, reservation *StockReservation) (*Product, error)
+	// Commit removes the quantity of an active reservation from the stock
+	// and records movement for it, filling in its product and quantity.
+	Commit(ctx context.Context, id string, movement *StockMovement) (*StockReservation, *Product, error)
+	Release(ctx context.Context, id string, now time.Time) (*StockReservation, *Product, error)
+	// ReleaseExpired expires the active reservations past their expiry at
+	// now and returns how many it expired.
+	ReleaseExpired(ctx context.Context, now time.Time) (int, error)
+}
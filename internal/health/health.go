@@ -0,0 +1,178 @@
+// Package health reports liveness and readiness over HTTP and the standard
+// grpc.health.v1 service.
+package health
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// checkTimeout bounds a single readiness check.
+const checkTimeout = 2 * time.Second
+
+// Checker decides whether the service is ready: the database answers a ping
+// and the schema is at the migration version the binary was built with.
+type Checker struct {
+	db               *sql.DB
+	migrationVersion uint
+	grpcHealth       *health.Server
+	shuttingDown     atomic.Bool
+
+	mu       sync.Mutex
+	services []string
+}
+
+func NewChecker(db *sql.DB, migrationVersion uint) *Checker {
+	grpcHealth := health.NewServer()
+	grpcHealth.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	return &Checker{
+		db:               db,
+		migrationVersion: migrationVersion,
+		grpcHealth:       grpcHealth,
+	}
+}
+
+// HealthServer is the grpc.health.v1 implementation to register on the gRPC server.
+func (c *Checker) HealthServer() healthpb.HealthServer {
+	return c.grpcHealth
+}
+
+// AddService reports a per-service status for name (e.g. pb.ProductService).
+func (c *Checker) AddService(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.services = append(c.services, name)
+	c.grpcHealth.SetServingStatus(name, healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+// Run checks readiness every interval and updates the gRPC serving status
+// until ctx is done.
+func (c *Checker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastErr error
+	for {
+		_, err := c.check(ctx)
+		if (err == nil) != (lastErr == nil) || err != nil && err.Error() != lastErr.Error() {
+			if err != nil {
+				slog.Warn("readiness check failed", "error", err)
+			} else {
+				slog.Info("readiness check passed")
+			}
+		}
+		lastErr = err
+		c.setStatus(err == nil)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown marks every service as not serving so load balancers stop
+// routing new requests while in-flight ones drain.
+func (c *Checker) Shutdown() {
+	c.shuttingDown.Store(true)
+	c.grpcHealth.Shutdown()
+}
+
+func (c *Checker) setStatus(ready bool) {
+	if c.shuttingDown.Load() {
+		return
+	}
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if ready {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.grpcHealth.SetServingStatus("", status)
+	for _, service := range c.services {
+		c.grpcHealth.SetServingStatus(service, status)
+	}
+}
+
+// check runs every readiness check and returns their individual results.
+func (c *Checker) check(ctx context.Context) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	results := map[string]string{}
+	var errs []error
+	record := func(name string, err error) {
+		if err != nil {
+			results[name] = err.Error()
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			return
+		}
+		results[name] = "ok"
+	}
+
+	if c.shuttingDown.Load() {
+		record("server", errors.New("shutting down"))
+	}
+	record("database", c.db.PingContext(ctx))
+	record("migrations", c.checkMigrations(ctx))
+
+	return results, errors.Join(errs...)
+}
+
+func (c *Checker) checkMigrations(ctx context.Context) error {
+	var version uint
+	var dirty bool
+	err := c.db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if err == sql.ErrNoRows {
+		return errors.New("no migrations applied")
+	}
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("migration %d is dirty", version)
+	}
+	if version < c.migrationVersion {
+		return fmt.Errorf("schema at version %d, want %d", version, c.migrationVersion)
+	}
+	return nil
+}
+
+// LivenessHandler serves /healthz. It only reports that the process is up.
+func (c *Checker) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"status": "ok"})
+	})
+}
+
+// ReadinessHandler serves /readyz: 200 when every check passes, 503 otherwise.
+func (c *Checker) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		results, err := c.check(r.Context())
+		if err != nil {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]any{"status": "not ready", "checks": results})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"status": "ready", "checks": results})
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
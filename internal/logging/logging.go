@@ -0,0 +1,78 @@
+// Package logging builds the structured JSON logger and carries
+// request-scoped loggers through context.Context.
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"go-backend-service/internal/redact"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+type contextKey struct{}
+
+// New returns a JSON logger writing to w. Every string value, including the
+// message, goes through redact.String, proto messages through redact.Message
+// and HTTP headers or gRPC metadata through redact.Headers, so credentials
+// never reach the output.
+func New(w io.Writer, level slog.Leveler) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redactAttr,
+	}))
+}
+
+func redactAttr(groups []string, a slog.Attr) slog.Attr {
+	switch a.Value.Kind() {
+	case slog.KindString:
+		if redact.IsSensitiveHeader(a.Key) {
+			return slog.String(a.Key, redact.Placeholder)
+		}
+		return slog.String(a.Key, redact.String(a.Value.String()))
+	case slog.KindAny:
+		switch v := a.Value.Any().(type) {
+		case error:
+			return slog.String(a.Key, redact.String(v.Error()))
+		case proto.Message:
+			data, err := protojson.Marshal(redact.Message(v))
+			if err != nil {
+				return slog.String(a.Key, err.Error())
+			}
+			return slog.Any(a.Key, json.RawMessage(data))
+		case http.Header:
+			return slog.Any(a.Key, redact.Headers(v))
+		case metadata.MD:
+			return slog.Any(a.Key, redact.Headers(v))
+		}
+	}
+	return a
+}
+
+// ParseLevel parses debug, info, warn or error.
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	err := level.UnmarshalText([]byte(strings.ToLower(s)))
+	return level, err
+}
+
+// WithContext returns a copy of ctx carrying logger.
+func WithContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the request-scoped logger stored in ctx, or the
+// default logger when there is none.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
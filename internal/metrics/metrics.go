@@ -0,0 +1,127 @@
+// Package metrics collects Prometheus metrics for RPCs, HTTP requests, the
+// database pool and the build, and serves them in the exposition format.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics owns a registry so that every instance, e.g. one per test, starts
+// from zero.
+type Metrics struct {
+	registry     *prometheus.Registry
+	rpcHandled   *prometheus.CounterVec
+	rpcDuration  *prometheus.HistogramVec
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+}
+
+// New creates the metrics and registers the Go runtime, process, build and
+// sql.DBStats collectors. db may be nil.
+func New(db *sql.DB) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		rpcHandled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_handled_total",
+			Help: "Number of RPCs completed on the server, by method and code.",
+		}, []string{"grpc_service", "grpc_method", "grpc_code"}),
+		rpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_server_handling_seconds",
+			Help:    "Latency of RPCs handled by the server, by method and code.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"grpc_service", "grpc_method", "grpc_code"}),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_server_requests_total",
+			Help: "Number of HTTP requests completed, by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_server_request_duration_seconds",
+			Help:    "Latency of HTTP requests, by method, route and status.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+	}
+
+	m.registry.MustRegister(
+		m.rpcHandled,
+		m.rpcDuration,
+		m.httpRequests,
+		m.httpDuration,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewBuildInfoCollector(),
+		buildInfo(),
+	)
+	if db != nil {
+		m.registry.MustRegister(collectors.NewDBStatsCollector(db, "postgres"))
+	}
+	return m
+}
+
+// MustRegister adds collectors owned by other packages to the registry.
+func (m *Metrics) MustRegister(cs ...prometheus.Collector) {
+	m.registry.MustRegister(cs...)
+}
+
+// Handler serves the metrics for a Prometheus scrape.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Gatherer exposes the registry, e.g. for testutil.GatherAndCompare.
+func (m *Metrics) Gatherer() prometheus.Gatherer {
+	return m.registry
+}
+
+// ObserveRPC records a completed RPC. fullMethod is /package.Service/Method.
+func (m *Metrics) ObserveRPC(fullMethod, code string, duration time.Duration) {
+	service, method := splitMethod(fullMethod)
+	m.rpcHandled.WithLabelValues(service, method, code).Inc()
+	m.rpcDuration.WithLabelValues(service, method, code).Observe(duration.Seconds())
+}
+
+// ObserveHTTP records a completed HTTP request. route must be a template
+// (/v1/products/{id}), not the raw path, to keep the label set bounded.
+func (m *Metrics) ObserveHTTP(method, route, status string, duration time.Duration) {
+	m.httpRequests.WithLabelValues(method, route, status).Inc()
+	m.httpDuration.WithLabelValues(method, route, status).Observe(duration.Seconds())
+}
+
+func splitMethod(fullMethod string) (service, method string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "unknown", fullMethod
+}
+
+// buildInfo exports the module version and VCS revision the binary was
+// built from as labels of a constant gauge.
+func buildInfo() prometheus.Collector {
+	version, revision, goVersion := "unknown", "unknown", "unknown"
+	if info, ok := debug.ReadBuildInfo(); ok {
+		version = info.Main.Version
+		goVersion = info.GoVersion
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" {
+				revision = s.Value
+			}
+		}
+	}
+	return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "go_backend_service_build_info",
+		Help: "Build information of the service; the value is always 1.",
+		ConstLabels: prometheus.Labels{
+			"version":    version,
+			"revision":   revision,
+			"go_version": goVersion,
+		},
+	}, func() float64 { return 1 })
+}
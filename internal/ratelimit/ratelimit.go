@@ -0,0 +1,124 @@
+// Package ratelimit implements in-memory token buckets keyed by client.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Quota allows Burst requests at once, refilled at Rate requests per second.
+type Quota struct {
+	Rate  float64
+	Burst int
+}
+
+// Result describes a bucket after a request was counted against it.
+type Result struct {
+	Allowed bool
+	// Limit is the bucket size and Remaining the requests left in it.
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed. Zero when
+	// the request was allowed.
+	RetryAfter time.Duration
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	quota  Quota
+}
+
+// Limiter holds one token bucket per key. Buckets that have refilled are
+// dropped by Run.
+type Limiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+func New() *Limiter {
+	return &Limiter{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// Allow takes one token from the bucket of key, creating it full with quota
+// if needed. A quota with a non-positive Burst allows every request.
+func (l *Limiter) Allow(key string, quota Quota) Result {
+	if quota.Burst <= 0 {
+		return Result{Allowed: true}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b, ok := l.buckets[key]
+	if !ok || b.quota != quota {
+		b = &bucket{tokens: float64(quota.Burst), last: now, quota: quota}
+		l.buckets[key] = b
+	}
+	b.refill(now)
+
+	result := Result{Limit: quota.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = b.timeUntil(1)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = b.timeUntil(float64(quota.Burst))
+	return result
+}
+
+// Run drops full buckets every interval until ctx is done, so that idle
+// clients do not accumulate.
+func (l *Limiter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			l.evict()
+		}
+	}
+}
+
+func (l *Limiter) evict() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for key, b := range l.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.quota.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(b.quota.Burst), b.tokens+elapsed*b.quota.Rate)
+	}
+	b.last = now
+}
+
+// timeUntil returns how long the bucket needs to hold tokens tokens.
+func (b *bucket) timeUntil(tokens float64) time.Duration {
+	missing := tokens - b.tokens
+	if missing <= 0 {
+		return 0
+	}
+	if b.quota.Rate <= 0 {
+		return math.MaxInt64
+	}
+	return time.Duration(missing / b.quota.Rate * float64(time.Second))
+}
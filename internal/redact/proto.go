@@ -0,0 +1,71 @@
+package redact
+
+import (
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+var (
+	mu              sync.RWMutex
+	sensitiveFields = map[protoreflect.FullName]bool{}
+)
+
+// RegisterSensitiveFields marks proto fields, by full name (e.g.
+// pb.CreateApiKeyResponse.key), as sensitive in addition to fields declared
+// with the [debug_redact = true] option.
+func RegisterSensitiveFields(names ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, name := range names {
+		sensitiveFields[protoreflect.FullName(name)] = true
+	}
+}
+
+func isSensitiveField(fd protoreflect.FieldDescriptor) bool {
+	if opts, ok := fd.Options().(*descriptorpb.FieldOptions); ok && opts.GetDebugRedact() {
+		return true
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	return sensitiveFields[fd.FullName()]
+}
+
+// Message returns a copy of msg with sensitive fields masked: strings are
+// replaced by Placeholder and any other value is cleared. msg is not modified.
+func Message(msg proto.Message) proto.Message {
+	if msg == nil {
+		return nil
+	}
+	clone := proto.Clone(msg)
+	redactMessage(clone.ProtoReflect())
+	return clone
+}
+
+func redactMessage(m protoreflect.Message) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case isSensitiveField(fd):
+			if fd.Kind() == protoreflect.StringKind && !fd.IsList() && !fd.IsMap() {
+				m.Set(fd, protoreflect.ValueOfString(Placeholder))
+			} else {
+				m.Clear(fd)
+			}
+		case fd.IsList() && fd.Message() != nil:
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				redactMessage(list.Get(i).Message())
+			}
+		case fd.IsMap() && fd.MapValue().Message() != nil:
+			v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+				redactMessage(mv.Message())
+				return true
+			})
+		case fd.Message() != nil && !fd.IsMap():
+			redactMessage(v.Message())
+		}
+		return true
+	})
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go-backend-service/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+type postgresAPIKeyRepository struct {
+	db tracedDB
+}
+
+func NewPostgresAPIKeyRepository(db *sql.DB) domain.APIKeyRepository {
+	return &postgresAPIKeyRepository{db: tracedDB{db}}
+}
+
+const apiKeyColumns = "id, name, prefix, scopes, expires_at, last_used_at, revoked_at, created_at"
+
+func (r *postgresAPIKeyRepository) Create(ctx context.Context, apiKey *domain.APIKey, keyHash string) error {
+	query := `
+        INSERT INTO api_keys (id, name, prefix, key_hash, scopes, expires_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING created_at
+    `
+	return r.db.QueryRowContext(ctx, query,
+		apiKey.ID,
+		apiKey.Name,
+		apiKey.Prefix,
+		keyHash,
+		pq.Array(apiKey.Scopes),
+		apiKey.ExpiresAt,
+	).Scan(&apiKey.CreatedAt)
+}
+
+func (r *postgresAPIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = $1`
+	apiKey, err := scanAPIKey(r.db.QueryRowContext(ctx, query, keyHash))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return apiKey, nil
+}
+
+func (r *postgresAPIKeyRepository) List(ctx context.Context, includeRevoked bool) ([]*domain.APIKey, error) {
+	query := `
+        SELECT ` + apiKeyColumns + `
+        FROM api_keys
+        WHERE $1 OR revoked_at IS NULL
+        ORDER BY created_at DESC
+    `
+	rows, err := r.db.QueryContext(ctx, query, includeRevoked)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var apiKeys []*domain.APIKey
+	for rows.Next() {
+		apiKey, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+	return apiKeys, rows.Err()
+}
+
+// Revoke marks the key revoked. Revoking a revoked key keeps its original
+// revocation time.
+func (r *postgresAPIKeyRepository) Revoke(ctx context.Context, id string) (*domain.APIKey, error) {
+	query := `
+        UPDATE api_keys
+        SET revoked_at = COALESCE(revoked_at, CURRENT_TIMESTAMP)
+        WHERE id = $1
+        RETURNING ` + apiKeyColumns
+	apiKey, err := scanAPIKey(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrAPIKeyNotFound.WithMetadata("id", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return apiKey, nil
+}
+
+func (r *postgresAPIKeyRepository) TouchLastUsed(ctx context.Context, id string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE api_keys SET last_used_at = $1 WHERE id = $2", at, id)
+	return err
+}
+
+func scanAPIKey(row rowScanner) (*domain.APIKey, error) {
+	apiKey := &domain.APIKey{}
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+	err := row.Scan(
+		&apiKey.ID,
+		&apiKey.Name,
+		&apiKey.Prefix,
+		pq.Array(&apiKey.Scopes),
+		&expiresAt,
+		&lastUsedAt,
+		&revokedAt,
+		&apiKey.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	apiKey.ExpiresAt = nullTimePtr(expiresAt)
+	apiKey.LastUsedAt = nullTimePtr(lastUsedAt)
+	apiKey.RevokedAt = nullTimePtr(revokedAt)
+	return apiKey, nil
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
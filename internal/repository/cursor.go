@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+
+	"go-backend-service/internal/domain"
+)
+
+// Page tokens are base64url-encoded JSON of the sort key of the last row of
+// a page. Clients must treat them as opaque.
+
+func encodeCursor(cursor any) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor fills cursor from token, or returns domain.ErrInvalidPageToken.
+func decodeCursor(token string, cursor any) error {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return domain.ErrInvalidPageToken
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cursor); err != nil {
+		return domain.ErrInvalidPageToken
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"go-backend-service/internal/domain"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	want := pageCursor{Query: "abc", Values: []json.RawMessage{json.RawMessage(`12.5`), json.RawMessage(`"p-9"`)}}
+	var got pageCursor
+	if err := decodeCursor(encodeCursor(want), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decoded %#v, want %#v", got, want)
+	}
+}
+
+func TestPageTokenContinuesAfterLastRow(t *testing.T) {
+	query := domain.ListQuery{Filter: "stock > 0", OrderBy: "price desc"}
+	last := &domain.Product{ID: "p-9", Price: 12.5, Stock: 3}
+
+	plan, err := newListPlan(productListSpec, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := plan.nextPageToken(last)
+
+	// The next request builds a new plan from the same query.
+	next, err := newListPlan(productListSpec, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clauses, err := next.pageClauses(domain.PageRequest{Page: 1, Limit: 10, PageToken: token})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantClauses := "WHERE stock > $1 AND ((price < $2) OR (price = $2 AND id < $3)) ORDER BY price DESC, id DESC LIMIT $4"
+	if clauses != wantClauses {
+		t.Errorf("clauses =\n  %s\nwant\n  %s", clauses, wantClauses)
+	}
+	if want := []any{int64(0), 12.5, "p-9", int32(11)}; !reflect.DeepEqual(next.args, want) {
+		t.Errorf("args = %#v, want %#v", next.args, want)
+	}
+	checkSQL(t, productListSpec, clauses, next.args)
+}
+
+func TestPageTokenKeepsTimestamps(t *testing.T) {
+	createdAt := time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC)
+	plan, err := newListPlan(productListSpec, domain.ListQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := plan.nextPageToken(&domain.Product{ID: "p-1", CreatedAt: createdAt})
+
+	next, _ := newListPlan(productListSpec, domain.ListQuery{})
+	if _, err := next.afterToken(token); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := next.args[0].(time.Time); !ok || !got.Equal(createdAt) {
+		t.Errorf("created_at = %v, want %v", next.args[0], createdAt)
+	}
+}
+
+func TestPageTokenRejectsOtherQueries(t *testing.T) {
+	issued := domain.ListQuery{Filter: "stock > 0", OrderBy: "price desc"}
+	plan, err := newListPlan(productListSpec, issued)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := plan.nextPageToken(&domain.Product{ID: "p-9", Price: 12.5})
+
+	tests := map[string]domain.ListQuery{
+		"other filter":    {Filter: "stock > 1", OrderBy: "price desc"},
+		"no filter":       {OrderBy: "price desc"},
+		"other direction": {Filter: "stock > 0", OrderBy: "price asc"},
+		"other order":     {Filter: "stock > 0", OrderBy: "name desc"},
+		"extra key":       {Filter: "stock > 0", OrderBy: "price desc, name"},
+	}
+	for name, query := range tests {
+		other, err := newListPlan(productListSpec, query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := other.pageClauses(domain.PageRequest{Page: 1, Limit: 10, PageToken: token}); !errors.Is(err, domain.ErrInvalidPageToken) {
+			t.Errorf("%s: err = %v, want ErrInvalidPageToken", name, err)
+		}
+	}
+
+	// The default order is the same list as naming it.
+	defaults, _ := newListPlan(productListSpec, domain.ListQuery{})
+	named, _ := newListPlan(productListSpec, domain.ListQuery{OrderBy: productListSpec.defaultOrder})
+	if _, err := named.afterToken(defaults.nextPageToken(&domain.Product{ID: "p-1"})); err != nil {
+		t.Errorf("token of the default order rejected with it named: %v", err)
+	}
+}
+
+func TestPageTokenRejectsGarbage(t *testing.T) {
+	plan, err := newListPlan(productListSpec, domain.ListQuery{OrderBy: "price desc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	fp := plan.fingerprint
+	tests := map[string]string{
+		"not base64":         "not a token!",
+		"padded base64":      base64.URLEncoding.EncodeToString([]byte(`{"q":"` + fp + `","v":[1,"p"]}`)),
+		"standard alphabet":  "+/+/",
+		"not JSON":           encode("hello"),
+		"JSON array":         encode(`[1, "p"]`),
+		"truncated JSON":     encode(`{"q":"` + fp + `","v":[1,`),
+		"unknown field":      encode(`{"q":"` + fp + `","v":[1,"p"],"offset":100}`),
+		"no values":          encode(`{"q":"` + fp + `"}`),
+		"too few values":     encode(`{"q":"` + fp + `","v":[1]}`),
+		"too many values":    encode(`{"q":"` + fp + `","v":[1,"p","x"]}`),
+		"string for price":   encode(`{"q":"` + fp + `","v":["cheap","p"]}`),
+		"number for id":      encode(`{"q":"` + fp + `","v":[1,2]}`),
+		"wrong fingerprint":  encode(`{"q":"AAAAAAAA","v":[1,"p"]}`),
+		"SQL in fingerprint": encode(`{"q":"'; DROP TABLE products; --","v":[1,"p"]}`),
+	}
+	for name, token := range tests {
+		_, err := plan.pageClauses(domain.PageRequest{Page: 1, Limit: 10, PageToken: token})
+		if !errors.Is(err, domain.ErrInvalidPageToken) {
+			t.Errorf("%s: err = %v, want ErrInvalidPageToken", name, err)
+		}
+		// Served as INVALID_ARGUMENT, never as an internal error.
+		if !errors.Is(err, domain.ErrInvalidArgument) {
+			t.Errorf("%s: err = %v is not an invalid argument", name, err)
+		}
+	}
+
+	valid := encode(`{"q":"` + fp + `","v":[1,"p"]}`)
+	if _, err := plan.pageClauses(domain.PageRequest{Page: 1, Limit: 10, PageToken: valid}); err != nil {
+		t.Errorf("valid token: %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+
+	"go-backend-service/internal/domain"
+	"go-backend-service/internal/tracing"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = tracing.Tracer("go-backend-service/internal/repository")
+
+// tracedDB wraps *sql.DB so that every statement gets a client span. The span
+// carries the SQL text with its $n placeholders, never the argument values.
+type tracedDB struct {
+	*sql.DB
+}
+
+func (db tracedDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	defer span.End()
+	result, err := db.DB.ExecContext(ctx, query, args...)
+	recordError(span, err)
+	return result, err
+}
+
+// QueryContext returns rows whose span stays open until they are read to the
+// end or closed, so that it covers reading them too.
+func (db tracedDB) QueryContext(ctx context.Context, query string, args ...any) (*tracedRows, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	rows, err := db.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		recordError(span, err)
+		span.End()
+		return nil, err
+	}
+	return &tracedRows{Rows: rows, span: span}, nil
+}
+
+func (db tracedDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	ctx, span := startQuerySpan(ctx, query)
+	defer span.End()
+	row := db.DB.QueryRowContext(ctx, query, args...)
+	if err := row.Err(); err != sql.ErrNoRows {
+		recordError(span, err)
+	}
+	return row
+}
+
+// errNoRows marks a conditional write that matched no row, to be explained
+// by versionConflict.
+var errNoRows = errors.New("no rows affected")
+
+// versionConflict tells why a write on the row id of table, conditional on
+// its version, matched no row: the row is gone or its version changed. table
+// is always a constant of the calling repository.
+func (db tracedDB) versionConflict(ctx context.Context, table string, id any, notFound error) error {
+	var version int64
+	err := db.QueryRowContext(ctx, "SELECT version FROM "+table+" WHERE id = $1", id).Scan(&version)
+	if err == sql.ErrNoRows {
+		return notFound
+	}
+	if err != nil {
+		return err
+	}
+	return domain.ErrVersionMismatch.WithMetadata("current_version", strconv.FormatInt(version, 10))
+}
+
+// inTx runs fn in a transaction, committed when fn returns nil and rolled
+// back otherwise.
+func (db tracedDB) inTx(ctx context.Context, fn func(tx tracedTx) error) error {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tracedTx{tx}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// tracedTx is the transaction counterpart of tracedDB.
+type tracedTx struct {
+	*sql.Tx
+}
+
+func (tx tracedTx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	defer span.End()
+	result, err := tx.Tx.ExecContext(ctx, query, args...)
+	recordError(span, err)
+	return result, err
+}
+
+func (tx tracedTx) QueryContext(ctx context.Context, query string, args ...any) (*tracedRows, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	rows, err := tx.Tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		recordError(span, err)
+		span.End()
+		return nil, err
+	}
+	return &tracedRows{Rows: rows, span: span}, nil
+}
+
+// tracedRows ends the span of its query once the rows are exhausted or
+// closed, recording the errors met while scanning and iterating them.
+type tracedRows struct {
+	*sql.Rows
+	span trace.Span
+	done bool
+}
+
+func (r *tracedRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.end()
+	return false
+}
+
+func (r *tracedRows) Scan(dest ...any) error {
+	err := r.Rows.Scan(dest...)
+	recordError(r.span, err)
+	return err
+}
+
+func (r *tracedRows) Close() error {
+	err := r.Rows.Close()
+	r.end()
+	return err
+}
+
+func (r *tracedRows) end() {
+	if r.done {
+		return
+	}
+	r.done = true
+	recordError(r.span, r.Rows.Err())
+	r.span.End()
+}
+
+func (tx tracedTx) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	ctx, span := startQuerySpan(ctx, query)
+	defer span.End()
+	row := tx.Tx.QueryRowContext(ctx, query, args...)
+	if err := row.Err(); err != sql.ErrNoRows {
+		recordError(span, err)
+	}
+	return row
+}
+
+func startQuerySpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	statement := strings.Join(strings.Fields(query), " ")
+	operation, _, _ := strings.Cut(statement, " ")
+	operation = strings.ToUpper(operation)
+	return tracer.Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(statement),
+		),
+	)
+}
+
+func recordError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
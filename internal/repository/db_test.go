@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var errConnectionLost = errors.New("connection lost")
+
+// rowsDriver answers every query with the rows 1 and 2, then fails with
+// errConnectionLost when the query is "SELECT broken".
+type rowsDriver struct{}
+
+func (rowsDriver) Open(string) (driver.Conn, error) { return rowsConn{}, nil }
+
+type rowsConn struct{}
+
+func (rowsConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (rowsConn) Close() error                        { return nil }
+func (rowsConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (rowsConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return &fakeRows{values: []int64{1, 2}, broken: query == "SELECT broken"}, nil
+}
+
+type fakeRows struct {
+	values []int64
+	broken bool
+}
+
+func (r *fakeRows) Columns() []string { return []string{"n"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		if r.broken {
+			return errConnectionLost
+		}
+		return io.EOF
+	}
+	dest[0], r.values = r.values[0], r.values[1:]
+	return nil
+}
+
+func init() {
+	sql.Register("repository-test-rows", rowsDriver{})
+}
+
+func TestTracedRowsSpanCoversIteration(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	sqlDB, err := sql.Open("repository-test-rows", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+	db := tracedDB{sqlDB}
+	ctx := context.Background()
+
+	rows, err := db.QueryContext(ctx, "SELECT n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		t.Fatal("no first row")
+	}
+	if len(recorder.Ended()) != 0 {
+		t.Fatal("span ended before the rows were read")
+	}
+	var n int64
+	for rows.Next() {
+		if err := rows.Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if ended := recorder.Ended(); len(ended) != 1 || ended[0].Status().Code != codes.Unset {
+		t.Fatalf("after the last row: ended spans = %v, want one without error", ended)
+	}
+
+	broken, err := db.QueryContext(ctx, "SELECT broken")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for broken.Next() {
+	}
+	if !errors.Is(broken.Err(), errConnectionLost) {
+		t.Fatalf("Err() = %v, want errConnectionLost", broken.Err())
+	}
+	broken.Close()
+	ended := recorder.Ended()
+	if len(ended) != 2 {
+		t.Fatalf("%d spans ended, want 2", len(ended))
+	}
+	if span := ended[1]; span.Status().Code != codes.Error || span.Status().Description != errConnectionLost.Error() {
+		t.Errorf("status = %+v, want the iteration error", span.Status())
+	}
+
+	// A scan error is recorded too, and closing early ends the span.
+	scanned, err := db.QueryContext(ctx, "SELECT n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	scanned.Next()
+	var s struct{}
+	if err := scanned.Scan(&s); err == nil {
+		t.Fatal("scanning into a struct succeeded")
+	}
+	scanned.Close()
+	ended = recorder.Ended()
+	if len(ended) != 3 || ended[2].Status().Code != codes.Error {
+		t.Errorf("scan error not recorded: %v", ended)
+	}
+}
@@ -3,48 +3,42 @@ package repository
 import (
 	"context"
 	"database/sql"
-	"fmt"
-	"go-backend-service/pkg/pb"
-)
+	"strconv"
 
-type DipanTypeRepository interface {
-	Create(ctx context.Context, dipanType *pb.DipanType) error
-	GetByID(ctx context.Context, id int32) (*pb.DipanType, error)
-	List(ctx context.Context, page, limit int32) ([]*pb.DipanType, int32, error)
-	Update(ctx context.Context, dipanType *pb.DipanType) error
-	Delete(ctx context.Context, id int32) error
-}
+	"go-backend-service/internal/domain"
+)
 
 type postgresDipanTypeRepository struct {
-	db *sql.DB
+	db tracedDB
 }
 
-func NewPostgresDipanTypeRepository(db *sql.DB) DipanTypeRepository {
-	return &postgresDipanTypeRepository{db: db}
+func NewPostgresDipanTypeRepository(db *sql.DB) domain.DipanTypeRepository {
+	return &postgresDipanTypeRepository{db: tracedDB{db}}
 }
 
-func (r *postgresDipanTypeRepository) Create(ctx context.Context, dipanType *pb.DipanType) error {
+func (r *postgresDipanTypeRepository) Create(ctx context.Context, dipanType *domain.DipanType) error {
 	query := `
         INSERT INTO dipan_types (nama_type)
         VALUES ($1)
+        RETURNING id, created_at, updated_at, version
     `
-	_, err := r.db.ExecContext(ctx, query, dipanType.NamaType)
-	return err
+	return r.db.QueryRowContext(ctx, query, dipanType.NamaType).Scan(
+		&dipanType.ID,
+		&dipanType.CreatedAt,
+		&dipanType.UpdatedAt,
+		&dipanType.Version,
+	)
 }
 
-func (r *postgresDipanTypeRepository) GetByID(ctx context.Context, id int32) (*pb.DipanType, error) {
+func (r *postgresDipanTypeRepository) GetByID(ctx context.Context, id int32) (*domain.DipanType, error) {
 	query := `
-        SELECT id, nama_type
+        SELECT id, nama_type, created_at, updated_at, version
         FROM dipan_types
         WHERE id = $1
     `
-	dipanType := &pb.DipanType{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&dipanType.Id,
-		&dipanType.NamaType,
-	)
+	dipanType, err := scanDipanType(r.db.QueryRowContext(ctx, query, id))
 	if err == sql.ErrNoRows {
-		return nil, err
+		return nil, dipanTypeNotFound(id)
 	}
 	if err != nil {
 		return nil, err
@@ -52,46 +46,118 @@ func (r *postgresDipanTypeRepository) GetByID(ctx context.Context, id int32) (*p
 	return dipanType, nil
 }
 
-func (r *postgresDipanTypeRepository) List(ctx context.Context, page, limit int32) ([]*pb.DipanType, int32, error) {
-	offset := (page - 1) * limit
-	query := `
-		SELECT id, nama_type
-		FROM dipan_types
-		ORDER BY id DESC
-		LIMIT $1 OFFSET $2
-	`
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+// dipanTypeListSpec lists the fields of ListDipanTypes filters and order_by.
+var dipanTypeListSpec = listSpec[*domain.DipanType]{
+	fields: map[string]listField[*domain.DipanType]{
+		"id":        {column: "id", kind: kindInteger, filterable: true, sortable: true, value: func(d *domain.DipanType) any { return d.ID }},
+		"nama_type": {column: "nama_type", kind: kindString, filterable: true, sortable: true, value: func(d *domain.DipanType) any { return d.NamaType }},
+	},
+	defaultOrder: "id desc",
+	tiebreaker:   "id",
+}
+
+// List returns the dipan types matching query.Filter in query.OrderBy order,
+// by descending id by default. A page token continues after the sort key of
+// the last dipan type of the previous page.
+func (r *postgresDipanTypeRepository) List(ctx context.Context, query domain.ListQuery) ([]*domain.DipanType, domain.PageResult, error) {
+	var result domain.PageResult
+
+	plan, err := newListPlan(dipanTypeListSpec, query)
+	if err != nil {
+		return nil, result, err
+	}
+	clauses, err := plan.pageClauses(query.Page)
+	if err != nil {
+		return nil, result, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, nama_type, created_at, updated_at, version
+		FROM dipan_types `+clauses, plan.args...)
 	if err != nil {
-		return nil, 0, err
+		return nil, result, err
 	}
 	defer rows.Close()
 
-	var dipanTypes []*pb.DipanType
+	var dipanTypes []*domain.DipanType
 	for rows.Next() {
-		dipanType := &pb.DipanType{}
-		err := rows.Scan(
-			&dipanType.Id,
-			&dipanType.NamaType,
-		)
+		dipanType, err := scanDipanType(rows)
 		if err != nil {
-			return nil, 0, err
+			return nil, result, err
 		}
 		dipanTypes = append(dipanTypes, dipanType)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, result, err
+	}
+
+	// One extra row was fetched to tell whether there is a next page
+	if len(dipanTypes) > int(query.Page.Limit) {
+		dipanTypes = dipanTypes[:query.Page.Limit]
+		result.NextPageToken = plan.nextPageToken(dipanTypes[len(dipanTypes)-1])
+	}
+
+	if query.Page.IncludeTotal {
+		countPlan, _ := newListPlan(dipanTypeListSpec, query)
+		err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM dipan_types "+countPlan.where(), countPlan.args...).Scan(&result.Total)
+		if err != nil {
+			return nil, result, err
+		}
+	}
+
+	return dipanTypes, result, nil
+}
+
+func (r *postgresDipanTypeRepository) Update(ctx context.Context, dipanType *domain.DipanType) error {
+	query := `
+        UPDATE dipan_types
+        SET nama_type = $1, updated_at = CURRENT_TIMESTAMP, version = version + 1
+        WHERE id = $2 AND ($3::bigint = 0 OR version = $3)
+        RETURNING created_at, updated_at, version
+    `
+	err := r.db.QueryRowContext(ctx, query, dipanType.NamaType, dipanType.ID, dipanType.Version).Scan(
+		&dipanType.CreatedAt,
+		&dipanType.UpdatedAt,
+		&dipanType.Version,
+	)
+	if err == sql.ErrNoRows {
+		return r.db.versionConflict(ctx, "dipan_types", dipanType.ID, dipanTypeNotFound(dipanType.ID))
+	}
+	return err
+}
 
-	// Get total count
-	var total int32
-	err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM dipan_types").Scan(&total)
+func (r *postgresDipanTypeRepository) Delete(ctx context.Context, id int32, version int64) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM dipan_types WHERE id = $1 AND ($2::bigint = 0 OR version = $2)", id, version)
 	if err != nil {
-		return nil, 0, err
+		return err
+	}
+	err = checkRowsAffected(result, errNoRows)
+	if err == errNoRows {
+		return r.db.versionConflict(ctx, "dipan_types", id, dipanTypeNotFound(id))
 	}
+	return err
+}
 
-	return dipanTypes, total, nil
+func dipanTypeNotFound(id int32) error {
+	return domain.ErrDipanTypeNotFound.WithMetadata("id", strconv.Itoa(int(id)))
 }
 
-func (r *postgresDipanTypeRepository) Update(ctx context.Context, dipanType *pb.DipanType) error {
-	return fmt.Errorf("not implemented")
+// rowScanner is implemented by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
 }
-func (r *postgresDipanTypeRepository) Delete(ctx context.Context, id int32) error {
-	return fmt.Errorf("not implemented")
+
+func scanDipanType(row rowScanner) (*domain.DipanType, error) {
+	dipanType := &domain.DipanType{}
+	err := row.Scan(
+		&dipanType.ID,
+		&dipanType.NamaType,
+		&dipanType.CreatedAt,
+		&dipanType.UpdatedAt,
+		&dipanType.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return dipanType, nil
 }
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// filterTerm is one comparison of an AIP-160 filter, e.g. price >= 10.
+type filterTerm struct {
+	field string
+	op    string
+	value string
+}
+
+// filterOps are the supported comparison operators. ":" is "has", which on
+// strings means contains.
+var filterOps = []string{"<=", ">=", "!=", "=", "<", ">", ":"}
+
+// parseFilter parses the subset of AIP-160 made of comparisons joined by
+// AND, e.g. `price >= 10 AND stock > 0 AND name:"meja"`. Values are bare
+// words or double-quoted strings with \" and \\ escapes.
+func parseFilter(filter string) ([]filterTerm, error) {
+	tokens, err := tokenizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var terms []filterTerm
+	for i := 0; i < len(tokens); {
+		if len(terms) > 0 {
+			if tokens[i].text != "AND" || tokens[i].quoted {
+				return nil, fmt.Errorf("expected AND, got %q", tokens[i].text)
+			}
+			i++
+		}
+		if i+3 > len(tokens) {
+			return nil, fmt.Errorf("incomplete comparison at end of filter")
+		}
+		field, op, value := tokens[i], tokens[i+1], tokens[i+2]
+		if field.quoted || field.op || !isFieldName(field.text) {
+			return nil, fmt.Errorf("expected a field name, got %q", field.text)
+		}
+		if !op.op {
+			return nil, fmt.Errorf("expected an operator after %s, got %q", field.text, op.text)
+		}
+		if value.op {
+			return nil, fmt.Errorf("expected a value after %s %s", field.text, op.text)
+		}
+		terms = append(terms, filterTerm{field: field.text, op: op.text, value: value.text})
+		i += 3
+	}
+	return terms, nil
+}
+
+type filterToken struct {
+	text   string
+	quoted bool
+	op     bool
+}
+
+func tokenizeFilter(filter string) ([]filterToken, error) {
+	var tokens []filterToken
+	runes := []rune(filter)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"':
+			var b strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				b.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			i++
+			tokens = append(tokens, filterToken{text: b.String(), quoted: true})
+		case strings.ContainsRune("<>=!:", r):
+			op := ""
+			for _, candidate := range filterOps {
+				if strings.HasPrefix(string(runes[i:]), candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unknown operator at %q", string(runes[i:]))
+			}
+			i += len(op)
+			tokens = append(tokens, filterToken{text: op, op: true})
+		case isWordRune(r):
+			start := i
+			for i < len(runes) && isWordRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, filterToken{text: string(runes[start:i])})
+		default:
+			return nil, fmt.Errorf("unexpected character %q", r)
+		}
+	}
+	return tokens, nil
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_.-+", r)
+}
+
+func isFieldName(s string) bool {
+	for i, r := range s {
+		if !(r == '_' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r))) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// orderTerm is one field of an AIP-132 order_by.
+type orderTerm struct {
+	field string
+	desc  bool
+}
+
+// parseOrderBy parses a comma-separated list of fields, each optionally
+// followed by asc or desc, e.g. "price desc, name".
+func parseOrderBy(orderBy string) ([]orderTerm, error) {
+	var terms []orderTerm
+	for _, part := range strings.Split(orderBy, ",") {
+		words := strings.Fields(part)
+		if len(words) == 0 || len(words) > 2 || !isFieldName(words[0]) {
+			return nil, fmt.Errorf("invalid order_by item %q", strings.TrimSpace(part))
+		}
+		term := orderTerm{field: words[0]}
+		if len(words) == 2 {
+			switch strings.ToLower(words[1]) {
+			case "asc":
+			case "desc":
+				term.desc = true
+			default:
+				return nil, fmt.Errorf("invalid direction %q, want asc or desc", words[1])
+			}
+		}
+		terms = append(terms, term)
+	}
+	return terms, nil
+}
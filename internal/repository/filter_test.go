@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		want   []filterTerm
+	}{
+		{"empty", "", nil},
+		{"blank", "   ", nil},
+		{"equal", "price = 10", []filterTerm{{"price", "=", "10"}}},
+		{"not equal", "stock != 0", []filterTerm{{"stock", "!=", "0"}}},
+		{"less", "price < 10", []filterTerm{{"price", "<", "10"}}},
+		{"less or equal", "price <= 10", []filterTerm{{"price", "<=", "10"}}},
+		{"greater", "price > 10", []filterTerm{{"price", ">", "10"}}},
+		{"greater or equal", "price >= 10.5", []filterTerm{{"price", ">=", "10.5"}}},
+		{"has", `name:"meja"`, []filterTerm{{"name", ":", "meja"}}},
+		{"no spaces", "price>=10", []filterTerm{{"price", ">=", "10"}}},
+		{"negative number", "stock > -1", []filterTerm{{"stock", ">", "-1"}}},
+		{"and", `price >= 10 AND stock > 0 AND name:meja`, []filterTerm{
+			{"price", ">=", "10"}, {"stock", ">", "0"}, {"name", ":", "meja"},
+		}},
+		{"quoted spaces", `name = "meja makan"`, []filterTerm{{"name", "=", "meja makan"}}},
+		{"quoted operators", `name = "a >= b AND c"`, []filterTerm{{"name", "=", "a >= b AND c"}}},
+		{"escaped quote", `name = "say \"hi\""`, []filterTerm{{"name", "=", `say "hi"`}}},
+		{"escaped backslash", `name = "C:\\temp"`, []filterTerm{{"name", "=", `C:\temp`}}},
+		{"empty string", `name = ""`, []filterTerm{{"name", "=", ""}}},
+		{"quoted timestamp", `created_at > "2024-01-01T00:00:00Z"`, []filterTerm{{"created_at", ">", "2024-01-01T00:00:00Z"}}},
+		{"quoted AND value", `name = "AND"`, []filterTerm{{"name", "=", "AND"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFilter(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseFilter(%q) = %v, want %v", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  string
+		wantErr string
+	}{
+		{"trailing AND", "price > 10 AND", "incomplete comparison"},
+		{"only AND", "AND", "incomplete comparison"},
+		{"leading AND", "AND price > 10", "expected an operator after AND"},
+		{"double AND", "price > 10 AND AND stock > 0", "expected an operator after AND"},
+		{"lowercase and", "price > 10 and stock > 0", `expected AND, got "and"`},
+		{"OR", "price > 10 OR stock > 0", `expected AND, got "OR"`},
+		{"quoted AND", `price > 10 "AND" stock > 0`, `expected AND, got "AND"`},
+		{"missing operator", "price 10 AND stock > 0", "expected an operator after price"},
+		{"missing value", "price >", "incomplete comparison"},
+		{"operator as value", "price = >", "expected a value after price ="},
+		{"reversed operator", "price => 10", "expected a value after price ="},
+		{"unknown operator", "price ! 10", "unknown operator"},
+		{"quoted field", `"name" = "meja"`, `expected a field name, got "name"`},
+		{"field starting with digit", "1price = 1", `expected a field name, got "1price"`},
+		{"field with dot", "name.first = x", `expected a field name, got "name.first"`},
+		{"unterminated string", `name = "meja`, "unterminated string"},
+		{"escaped closing quote", `name = "meja\"`, "unterminated string"},
+		{"single quotes", `name = 'meja'`, "unexpected character"},
+		{"semicolon", "price = 1; DROP TABLE products", "unexpected character"},
+		{"parentheses", "(price = 1)", "unexpected character"},
+		{"unquoted timestamp", "created_at > 2024-01-01T00:00:00Z", `expected AND, got ":"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			terms, err := parseFilter(tt.filter)
+			if err == nil {
+				t.Fatalf("parseFilter(%q) = %v, want an error", tt.filter, terms)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseFilter(%q) error = %q, want %q", tt.filter, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseOrderBy(t *testing.T) {
+	tests := []struct {
+		orderBy string
+		want    []orderTerm
+	}{
+		{"price", []orderTerm{{"price", false}}},
+		{"price asc", []orderTerm{{"price", false}}},
+		{"price desc", []orderTerm{{"price", true}}},
+		{"price DESC", []orderTerm{{"price", true}}},
+		{"  price   desc  ", []orderTerm{{"price", true}}},
+		{"price desc, name", []orderTerm{{"price", true}, {"name", false}}},
+		{"price desc,name asc,created_at desc", []orderTerm{{"price", true}, {"name", false}, {"created_at", true}}},
+		// Duplicates are rejected against the list spec, not here.
+		{"price, price desc", []orderTerm{{"price", false}, {"price", true}}},
+	}
+	for _, tt := range tests {
+		got, err := parseOrderBy(tt.orderBy)
+		if err != nil {
+			t.Errorf("parseOrderBy(%q): %v", tt.orderBy, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseOrderBy(%q) = %v, want %v", tt.orderBy, got, tt.want)
+		}
+	}
+}
+
+func TestParseOrderByErrors(t *testing.T) {
+	tests := []struct {
+		orderBy string
+		wantErr string
+	}{
+		{"", `invalid order_by item ""`},
+		{"price,", `invalid order_by item ""`},
+		{", price", `invalid order_by item ""`},
+		{"price sideways", `invalid direction "sideways"`},
+		{"price desc desc", `invalid order_by item "price desc desc"`},
+		{"price-desc", `invalid order_by item "price-desc"`},
+		{"price; DROP TABLE products", `invalid order_by item`},
+		{`"price"`, `invalid order_by item`},
+	}
+	for _, tt := range tests {
+		terms, err := parseOrderBy(tt.orderBy)
+		if err == nil {
+			t.Errorf("parseOrderBy(%q) = %v, want an error", tt.orderBy, terms)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("parseOrderBy(%q) error = %q, want %q", tt.orderBy, err, tt.wantErr)
+		}
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	tests := map[string]string{
+		"meja":      "meja",
+		"50%":       `50\%`,
+		"a_b":       `a\_b`,
+		`C:\temp`:   `C:\\temp`,
+		`100%_\off`: `100\%\_\\off`,
+	}
+	for in, want := range tests {
+		if got := escapeLike(in); got != want {
+			t.Errorf("escapeLike(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-backend-service/internal/domain"
+)
+
+// valueKind is the type of a listable column, used to parse filter values
+// and page token values.
+type valueKind int
+
+const (
+	kindString valueKind = iota
+	kindNumber
+	kindInteger
+	kindTime
+)
+
+// listField maps a filter/order_by field name to a column. Filter values are
+// always passed as query arguments, and only the column names declared here
+// are ever written into SQL.
+type listField[T any] struct {
+	column     string
+	kind       valueKind
+	filterable bool
+	sortable   bool
+	// value returns the field of an item, to build the next page token.
+	value func(T) any
+}
+
+type listSpec[T any] struct {
+	fields       map[string]listField[T]
+	defaultOrder string
+	// tiebreaker is a unique sortable field appended to every order so that
+	// page tokens always point between two rows.
+	tiebreaker string
+}
+
+type sortKey[T any] struct {
+	listField[T]
+	desc bool
+}
+
+// listPlan is a parsed ListQuery: the filter as SQL conditions with their
+// arguments, and the sort keys.
+type listPlan[T any] struct {
+	conditions  []string
+	args        []any
+	keys        []sortKey[T]
+	fingerprint string
+}
+
+func newListPlan[T any](spec listSpec[T], query domain.ListQuery) (*listPlan[T], error) {
+	p := &listPlan[T]{}
+
+	terms, err := parseFilter(query.Filter)
+	if err != nil {
+		return nil, invalidListField("filter", err.Error())
+	}
+	for _, term := range terms {
+		field, ok := spec.fields[term.field]
+		if !ok || !field.filterable {
+			return nil, invalidListField("filter", fmt.Sprintf("cannot filter on %q", term.field))
+		}
+		if err := p.addCondition(field, term); err != nil {
+			return nil, invalidListField("filter", err.Error())
+		}
+	}
+
+	orderBy := query.OrderBy
+	if strings.TrimSpace(orderBy) == "" {
+		orderBy = spec.defaultOrder
+	}
+	order, err := parseOrderBy(orderBy)
+	if err != nil {
+		return nil, invalidListField("order_by", err.Error())
+	}
+	seen := make(map[string]bool)
+	for _, term := range order {
+		field, ok := spec.fields[term.field]
+		if !ok || !field.sortable {
+			return nil, invalidListField("order_by", fmt.Sprintf("cannot order by %q", term.field))
+		}
+		if seen[term.field] {
+			return nil, invalidListField("order_by", fmt.Sprintf("%q appears twice", term.field))
+		}
+		seen[term.field] = true
+		p.keys = append(p.keys, sortKey[T]{listField: field, desc: term.desc})
+	}
+	if !seen[spec.tiebreaker] {
+		last := p.keys[len(p.keys)-1]
+		p.keys = append(p.keys, sortKey[T]{listField: spec.fields[spec.tiebreaker], desc: last.desc})
+	}
+
+	p.fingerprint = fingerprint(query.Filter, orderBy)
+	return p, nil
+}
+
+// fingerprint identifies the list a page token was issued for, so that it
+// cannot be replayed against another one.
+func fingerprint(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return base64.RawURLEncoding.EncodeToString(sum[:6])
+}
+
+func (p *listPlan[T]) arg(value any) string {
+	p.args = append(p.args, value)
+	return "$" + strconv.Itoa(len(p.args))
+}
+
+func (p *listPlan[T]) addCondition(field listField[T], term filterTerm) error {
+	if term.op == ":" {
+		if field.kind != kindString {
+			return fmt.Errorf("%s does not support ':'", term.field)
+		}
+		p.conditions = append(p.conditions, field.column+` ILIKE `+p.arg("%"+escapeLike(term.value)+"%"))
+		return nil
+	}
+	if field.kind == kindString && term.op != "=" && term.op != "!=" {
+		return fmt.Errorf("%s supports only =, != and ':'", term.field)
+	}
+
+	value, err := parseListValue(field.kind, term.value)
+	if err != nil {
+		return fmt.Errorf("%s: %v", term.field, err)
+	}
+	op := term.op
+	if op == "!=" {
+		op = "<>"
+	}
+	p.conditions = append(p.conditions, field.column+" "+op+" "+p.arg(value))
+	return nil
+}
+
+// require adds a condition on a column that is not part of the filter, such
+// as the parent of the listed items.
+func (p *listPlan[T]) require(column string, value any) {
+	p.conditions = append(p.conditions, column+" = "+p.arg(value))
+}
+
+// where returns the WHERE clause for the filter and extra conditions, or ""
+// when there are none.
+func (p *listPlan[T]) where(extra ...string) string {
+	conditions := append(append([]string(nil), p.conditions...), extra...)
+	if len(conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(conditions, " AND ")
+}
+
+// pageClauses returns the WHERE, ORDER BY, LIMIT and OFFSET clauses that
+// select page, fetching one extra row to detect a next page.
+func (p *listPlan[T]) pageClauses(page domain.PageRequest) (string, error) {
+	var extra []string
+	if page.PageToken != "" {
+		after, err := p.afterToken(page.PageToken)
+		if err != nil {
+			return "", err
+		}
+		extra = append(extra, after)
+	}
+
+	order := make([]string, len(p.keys))
+	for i, key := range p.keys {
+		order[i] = key.column + " ASC"
+		if key.desc {
+			order[i] = key.column + " DESC"
+		}
+	}
+
+	clauses := p.where(extra...) + " ORDER BY " + strings.Join(order, ", ") + " LIMIT " + p.arg(page.Limit+1)
+	if page.PageToken == "" {
+		clauses += " OFFSET " + p.arg(page.Offset())
+	}
+	return clauses, nil
+}
+
+// pageCursor is the content of a page token: the sort key values of the last
+// row and the fingerprint of the filter and order it was issued for.
+type pageCursor struct {
+	Query  string            `json:"q"`
+	Values []json.RawMessage `json:"v"`
+}
+
+func (p *listPlan[T]) nextPageToken(last T) string {
+	cursor := pageCursor{Query: p.fingerprint}
+	for _, key := range p.keys {
+		value, _ := json.Marshal(key.value(last))
+		cursor.Values = append(cursor.Values, value)
+	}
+	return encodeCursor(cursor)
+}
+
+// afterToken returns the keyset condition selecting the rows after the
+// token's row: (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ..., with < for
+// descending keys.
+func (p *listPlan[T]) afterToken(token string) (string, error) {
+	var cursor pageCursor
+	if err := decodeCursor(token, &cursor); err != nil {
+		return "", err
+	}
+	if cursor.Query != p.fingerprint || len(cursor.Values) != len(p.keys) {
+		return "", domain.ErrInvalidPageToken
+	}
+
+	placeholders := make([]string, len(p.keys))
+	for i, key := range p.keys {
+		value, err := decodeListValue(key.kind, cursor.Values[i])
+		if err != nil {
+			return "", domain.ErrInvalidPageToken
+		}
+		placeholders[i] = p.arg(value)
+	}
+
+	alternatives := make([]string, len(p.keys))
+	for i, key := range p.keys {
+		var terms []string
+		for j := 0; j < i; j++ {
+			terms = append(terms, p.keys[j].column+" = "+placeholders[j])
+		}
+		op := " > "
+		if key.desc {
+			op = " < "
+		}
+		terms = append(terms, key.column+op+placeholders[i])
+		alternatives[i] = "(" + strings.Join(terms, " AND ") + ")"
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")", nil
+}
+
+func parseListValue(kind valueKind, text string) (any, error) {
+	switch kind {
+	case kindNumber:
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("want a number")
+		}
+		return v, nil
+	case kindInteger:
+		v, err := strconv.ParseInt(text, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("want an integer")
+		}
+		return v, nil
+	case kindTime:
+		t, err := time.Parse(time.RFC3339, text)
+		if err != nil {
+			return nil, fmt.Errorf("want an RFC 3339 timestamp")
+		}
+		return t, nil
+	}
+	return text, nil
+}
+
+func decodeListValue(kind valueKind, raw json.RawMessage) (any, error) {
+	var err error
+	switch kind {
+	case kindNumber:
+		var v float64
+		err = json.Unmarshal(raw, &v)
+		return v, err
+	case kindInteger:
+		var v int64
+		err = json.Unmarshal(raw, &v)
+		return v, err
+	case kindTime:
+		var v time.Time
+		err = json.Unmarshal(raw, &v)
+		return v, err
+	}
+	var v string
+	err = json.Unmarshal(raw, &v)
+	return v, err
+}
+
+// escapeLike escapes the LIKE wildcards in s.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func invalidListField(field, message string) error {
+	return domain.NewError(domain.ErrInvalidArgument, "INVALID_FIELD", field+": "+message).
+		WithMetadata("field", field)
+}
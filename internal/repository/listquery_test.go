@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go-backend-service/internal/domain"
+)
+
+var placeholderPattern = regexp.MustCompile(`\$(\d+)`)
+
+// sqlKeywords are the words listPlan writes besides column names.
+var sqlKeywords = map[string]bool{
+	"WHERE": true, "AND": true, "OR": true, "ILIKE": true,
+	"ORDER": true, "BY": true, "ASC": true, "DESC": true, "LIMIT": true, "OFFSET": true,
+}
+
+// checkSQL asserts that clauses use the placeholders $1..$len(args), each at
+// least once, and that every identifier in it is a keyword or a column of
+// spec, so no part of the filter or order_by was written into the SQL.
+func checkSQL[T any](t *testing.T, spec listSpec[T], clauses string, args []any) {
+	t.Helper()
+	used := make(map[int]bool)
+	for _, match := range placeholderPattern.FindAllStringSubmatch(clauses, -1) {
+		n, _ := strconv.Atoi(match[1])
+		if n < 1 || n > len(args) {
+			t.Errorf("%s: placeholder $%d without an argument (%d args)", clauses, n, len(args))
+		}
+		used[n] = true
+	}
+	if len(used) != len(args) {
+		t.Errorf("%s: %d placeholders for %d args", clauses, len(used), len(args))
+	}
+
+	columns := make(map[string]bool)
+	for _, field := range spec.fields {
+		columns[field.column] = true
+	}
+	for _, word := range regexp.MustCompile(`[A-Za-z_]\w*`).FindAllString(placeholderPattern.ReplaceAllString(clauses, ""), -1) {
+		if !sqlKeywords[word] && !columns[word] {
+			t.Errorf("%s: %q is neither a keyword nor a whitelisted column", clauses, word)
+		}
+	}
+}
+
+func TestListPlanSQL(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name        string
+		query       domain.ListQuery
+		wantClauses string
+		wantArgs    []any
+	}{
+		{
+			name:        "defaults",
+			query:       domain.ListQuery{Page: domain.PageRequest{Page: 1, Limit: 10}},
+			wantClauses: " ORDER BY created_at DESC, id DESC LIMIT $1 OFFSET $2",
+			wantArgs:    []any{int32(11), int64(0)},
+		},
+		{
+			name: "every operator",
+			query: domain.ListQuery{
+				Filter:  `price >= 10 AND price < 99.5 AND stock != 0 AND stock <= 5 AND stock > -1 AND name = "Meja"`,
+				OrderBy: "price desc",
+				Page:    domain.PageRequest{Page: 3, Limit: 20},
+			},
+			wantClauses: "WHERE price >= $1 AND price < $2 AND stock <> $3 AND stock <= $4 AND stock > $5 AND name = $6" +
+				" ORDER BY price DESC, id DESC LIMIT $7 OFFSET $8",
+			wantArgs: []any{10.0, 99.5, int64(0), int64(5), int64(-1), "Meja", int32(21), int64(40)},
+		},
+		{
+			name: "has escapes LIKE wildcards",
+			query: domain.ListQuery{
+				Filter: `name:"50%_off" AND description:"a\\b"`,
+				Page:   domain.PageRequest{Page: 1, Limit: 10},
+			},
+			wantClauses: "WHERE name ILIKE $1 AND description ILIKE $2 ORDER BY created_at DESC, id DESC LIMIT $3 OFFSET $4",
+			wantArgs:    []any{`%50\%\_off%`, `%a\\b%`, int32(11), int64(0)},
+		},
+		{
+			name: "timestamp and ascending order",
+			query: domain.ListQuery{
+				Filter:  `created_at > "2024-01-02T03:04:05Z" AND name != "x' OR '1'='1"`,
+				OrderBy: "name, price desc",
+				Page:    domain.PageRequest{Page: 1, Limit: 5},
+			},
+			wantClauses: "WHERE created_at > $1 AND name <> $2 ORDER BY name ASC, price DESC, id DESC LIMIT $3 OFFSET $4",
+			wantArgs:    []any{createdAt, "x' OR '1'='1", int32(6), int64(0)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := newListPlan(productListSpec, tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			clauses, err := plan.pageClauses(tt.query.Page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if clauses != tt.wantClauses {
+				t.Errorf("clauses =\n  %s\nwant\n  %s", clauses, tt.wantClauses)
+			}
+			if !reflect.DeepEqual(plan.args, tt.wantArgs) {
+				t.Errorf("args = %#v, want %#v", plan.args, tt.wantArgs)
+			}
+			checkSQL(t, productListSpec, clauses, plan.args)
+		})
+	}
+}
+
+func TestListPlanRequireAndWhere(t *testing.T) {
+	plan, err := newListPlan(stockMovementListSpec, domain.ListQuery{Filter: `type = "sell"`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan.require("product_id", "p-1")
+	if got, want := plan.where(), "WHERE type = $1 AND product_id = $2"; got != want {
+		t.Errorf("where() = %q, want %q", got, want)
+	}
+	if want := []any{"sell", "p-1"}; !reflect.DeepEqual(plan.args, want) {
+		t.Errorf("args = %#v, want %#v", plan.args, want)
+	}
+
+	empty, err := newListPlan(productListSpec, domain.ListQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := empty.where(); got != "" {
+		t.Errorf("where() without conditions = %q, want empty", got)
+	}
+}
+
+func TestListPlanOrder(t *testing.T) {
+	tests := []struct {
+		orderBy string
+		want    string
+	}{
+		{"", "created_at DESC, id DESC"},
+		{"price", "price ASC, id ASC"},
+		{"price desc", "price DESC, id DESC"},
+		{"price desc, name", "price DESC, name ASC, id ASC"},
+		{"stock asc, updated_at desc", "stock ASC, updated_at DESC, id DESC"},
+	}
+	for _, tt := range tests {
+		plan, err := newListPlan(productListSpec, domain.ListQuery{OrderBy: tt.orderBy})
+		if err != nil {
+			t.Errorf("order_by %q: %v", tt.orderBy, err)
+			continue
+		}
+		clauses, err := plan.pageClauses(domain.PageRequest{Page: 1, Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(clauses, "ORDER BY "+tt.want+" LIMIT") {
+			t.Errorf("order_by %q: clauses = %q, want ORDER BY %s", tt.orderBy, clauses, tt.want)
+		}
+	}
+}
+
+func TestListPlanErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   domain.ListQuery
+		field   string
+		wantErr string
+	}{
+		{"unknown filter field", domain.ListQuery{Filter: "secret = 1"}, "filter", `cannot filter on "secret"`},
+		{"unfilterable field", domain.ListQuery{Filter: `id = "p-1"`}, "filter", `cannot filter on "id"`},
+		{"string compared to price", domain.ListQuery{Filter: `price > "cheap"`}, "filter", "price: want a number"},
+		{"fraction for stock", domain.ListQuery{Filter: "stock = 1.5"}, "filter", "stock: want an integer"},
+		{"stock beyond int32", domain.ListQuery{Filter: "stock > 3000000000"}, "filter", "stock: want an integer"},
+		{"bad timestamp", domain.ListQuery{Filter: `created_at > "yesterday"`}, "filter", "created_at: want an RFC 3339 timestamp"},
+		{"date without time", domain.ListQuery{Filter: `created_at > "2024-01-02"`}, "filter", "want an RFC 3339 timestamp"},
+		{"ordering a string", domain.ListQuery{Filter: `name > "a"`}, "filter", "name supports only =, != and ':'"},
+		{"has on a number", domain.ListQuery{Filter: "price:10"}, "filter", "price does not support ':'"},
+		{"parse error", domain.ListQuery{Filter: "price > 10 AND"}, "filter", "incomplete comparison"},
+		{"unknown order field", domain.ListQuery{OrderBy: "secret"}, "order_by", `cannot order by "secret"`},
+		{"unsortable field", domain.ListQuery{OrderBy: "description"}, "order_by", `cannot order by "description"`},
+		{"tiebreaker is not sortable", domain.ListQuery{OrderBy: "id"}, "order_by", `cannot order by "id"`},
+		{"duplicate order key", domain.ListQuery{OrderBy: "price, price desc"}, "order_by", `"price" appears twice`},
+		{"bad direction", domain.ListQuery{OrderBy: "price up"}, "order_by", `invalid direction "up"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newListPlan(productListSpec, tt.query)
+			if !errors.Is(err, domain.ErrInvalidArgument) {
+				t.Fatalf("err = %v, want an invalid argument", err)
+			}
+			var domainErr *domain.Error
+			if !errors.As(err, &domainErr) || domainErr.Reason != "INVALID_FIELD" || domainErr.Metadata["field"] != tt.field {
+				t.Errorf("err = %#v, want INVALID_FIELD on %s", err, tt.field)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %q, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -3,23 +3,24 @@ package repository
 import (
 	"context"
 	"database/sql"
-	"fmt"
 
 	"go-backend-service/internal/domain"
 )
 
 type postgresProductRepository struct {
-	db *sql.DB
+	db tracedDB
 }
 
 func NewPostgresProductRepository(db *sql.DB) domain.ProductRepository {
-	return &postgresProductRepository{db: db}
+	return &postgresProductRepository{db: tracedDB{db}}
 }
 
+const productColumns = "id, name, description, price, stock, reserved, created_at, updated_at, version"
+
 func (r *postgresProductRepository) Create(ctx context.Context, product *domain.Product) error {
 	query := `
-        INSERT INTO products (id, name, description, price, stock, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        INSERT INTO products (id, name, description, price, stock, created_at, updated_at, version)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
     `
 
 	_, err := r.db.ExecContext(ctx, query,
@@ -30,6 +31,7 @@ func (r *postgresProductRepository) Create(ctx context.Context, product *domain.
 		product.Stock,
 		product.CreatedAt,
 		product.UpdatedAt,
+		product.Version,
 	)
 
 	return err
@@ -37,24 +39,14 @@ func (r *postgresProductRepository) Create(ctx context.Context, product *domain.
 
 func (r *postgresProductRepository) GetByID(ctx context.Context, id string) (*domain.Product, error) {
 	query := `
-        SELECT id, name, description, price, stock, created_at, updated_at
+        SELECT ` + productColumns + `
         FROM products
         WHERE id = $1
     `
 
-	product := &domain.Product{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&product.ID,
-		&product.Name,
-		&product.Description,
-		&product.Price,
-		&product.Stock,
-		&product.CreatedAt,
-		&product.UpdatedAt,
-	)
-
+	product, err := scanProduct(r.db.QueryRowContext(ctx, query, id))
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("product not found")
+		return nil, domain.ErrProductNotFound.WithMetadata("id", id)
 	}
 
 	if err != nil {
@@ -64,46 +56,221 @@ func (r *postgresProductRepository) GetByID(ctx context.Context, id string) (*do
 	return product, nil
 }
 
-func (r *postgresProductRepository) List(ctx context.Context, page, limit int32) ([]*domain.Product, int32, error) {
-	offset := (page - 1) * limit
+// productListSpec lists the fields of ListProducts filters and order_by.
+var productListSpec = listSpec[*domain.Product]{
+	fields: map[string]listField[*domain.Product]{
+		"id":          {column: "id", kind: kindString, value: func(p *domain.Product) any { return p.ID }},
+		"name":        {column: "name", kind: kindString, filterable: true, sortable: true, value: func(p *domain.Product) any { return p.Name }},
+		"description": {column: "description", kind: kindString, filterable: true},
+		"price":       {column: "price", kind: kindNumber, filterable: true, sortable: true, value: func(p *domain.Product) any { return p.Price }},
+		"stock":       {column: "stock", kind: kindInteger, filterable: true, sortable: true, value: func(p *domain.Product) any { return p.Stock }},
+		"created_at":  {column: "created_at", kind: kindTime, filterable: true, sortable: true, value: func(p *domain.Product) any { return p.CreatedAt }},
+		"updated_at":  {column: "updated_at", kind: kindTime, filterable: true, sortable: true, value: func(p *domain.Product) any { return p.UpdatedAt }},
+	},
+	defaultOrder: "created_at desc",
+	tiebreaker:   "id",
+}
 
-	query := `
-        SELECT id, name, description, price, stock, created_at, updated_at
-        FROM products
-        ORDER BY created_at DESC
-        LIMIT $1 OFFSET $2
-    `
+// List returns the products matching query.Filter in query.OrderBy order,
+// newest first by default. A page token continues after the sort key of the
+// last product of the previous page.
+func (r *postgresProductRepository) List(ctx context.Context, query domain.ListQuery) ([]*domain.Product, domain.PageResult, error) {
+	var result domain.PageResult
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	plan, err := newListPlan(productListSpec, query)
 	if err != nil {
-		return nil, 0, err
+		return nil, result, err
+	}
+	clauses, err := plan.pageClauses(query.Page)
+	if err != nil {
+		return nil, result, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT `+productColumns+`
+        FROM products `+clauses, plan.args...)
+	if err != nil {
+		return nil, result, err
 	}
 	defer rows.Close()
 
 	var products []*domain.Product
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			return nil, result, err
+		}
+		products = append(products, product)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, result, err
+	}
+
+	// One extra row was fetched to tell whether there is a next page
+	if len(products) > int(query.Page.Limit) {
+		products = products[:query.Page.Limit]
+		result.NextPageToken = plan.nextPageToken(products[len(products)-1])
+	}
+
+	if query.Page.IncludeTotal {
+		countPlan, _ := newListPlan(productListSpec, query)
+		err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM products "+countPlan.where(), countPlan.args...).Scan(&result.Total)
+		if err != nil {
+			return nil, result, err
+		}
+	}
+
+	return products, result, nil
+}
+
+// productSearchSpec orders search results by rank. Searches take no filter
+// or order_by; the spec only drives keyset pagination.
+var productSearchSpec = listSpec[*domain.ProductSearchResult]{
+	fields: map[string]listField[*domain.ProductSearchResult]{
+		"id":   {column: "id", kind: kindString, value: func(r *domain.ProductSearchResult) any { return r.Product.ID }},
+		"rank": {column: "rank", kind: kindNumber, sortable: true, value: func(r *domain.ProductSearchResult) any { return r.Rank }},
+	},
+	defaultOrder: "rank desc",
+	tiebreaker:   "id",
+}
+
+// Search matches text against the search_vector column, most relevant
+// first. Snippets are only computed for the rows of the page.
+func (r *postgresProductRepository) Search(ctx context.Context, text string, page domain.PageRequest) ([]*domain.ProductSearchResult, domain.PageResult, error) {
+	var result domain.PageResult
+
+	plan, err := newListPlan(productSearchSpec, domain.ListQuery{})
+	if err != nil {
+		return nil, result, err
+	}
+	plan.fingerprint = fingerprint("search", text)
+	textArg := plan.arg(text)
+	clauses, err := plan.pageClauses(page)
+	if err != nil {
+		return nil, result, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT id, name, description, price, stock, reserved, created_at, updated_at, version, rank,
+            ts_headline('simple', concat_ws(' - ', name, description), query,
+                'StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2')
+        FROM (
+            SELECT p.id, p.name, p.description, p.price, p.stock, p.reserved, p.created_at, p.updated_at, p.version,
+                ts_rank(p.search_vector, query)::float8 AS rank, query
+            FROM products p, websearch_to_tsquery('simple', `+textArg+`) query
+            WHERE p.search_vector @@ query
+        ) results `+clauses, plan.args...)
+	if err != nil {
+		return nil, result, err
+	}
+	defer rows.Close()
+
+	var results []*domain.ProductSearchResult
 	for rows.Next() {
 		product := &domain.Product{}
+		res := &domain.ProductSearchResult{Product: product}
 		err := rows.Scan(
 			&product.ID,
 			&product.Name,
 			&product.Description,
 			&product.Price,
 			&product.Stock,
+			&product.Reserved,
 			&product.CreatedAt,
 			&product.UpdatedAt,
+			&product.Version,
+			&res.Rank,
+			&res.Snippet,
 		)
 		if err != nil {
-			return nil, 0, err
+			return nil, result, err
 		}
-		products = append(products, product)
+		results = append(results, res)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, result, err
+	}
+
+	// One extra row was fetched to tell whether there is a next page
+	if len(results) > int(page.Limit) {
+		results = results[:page.Limit]
+		result.NextPageToken = plan.nextPageToken(results[len(results)-1])
+	}
+
+	if page.IncludeTotal {
+		err = r.db.QueryRowContext(ctx, `
+            SELECT COUNT(*) FROM products
+            WHERE search_vector @@ websearch_to_tsquery('simple', $1)`, text).Scan(&result.Total)
+		if err != nil {
+			return nil, result, err
+		}
+	}
+
+	return results, result, nil
+}
+
+func (r *postgresProductRepository) Update(ctx context.Context, product *domain.Product) error {
+	query := `
+        UPDATE products
+        SET name = $1, description = $2, price = $3, updated_at = $4, version = version + 1
+        WHERE id = $5 AND version = $6
+        RETURNING version
+    `
+
+	err := r.db.QueryRowContext(ctx, query,
+		product.Name,
+		product.Description,
+		product.Price,
+		product.UpdatedAt,
+		product.ID,
+		product.Version,
+	).Scan(&product.Version)
+	if err == sql.ErrNoRows {
+		return r.db.versionConflict(ctx, "products", product.ID, domain.ErrProductNotFound.WithMetadata("id", product.ID))
+	}
+	return err
+}
+
+func (r *postgresProductRepository) Delete(ctx context.Context, id string, version int64) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM products WHERE id = $1 AND ($2::bigint = 0 OR version = $2)", id, version)
+	if err != nil {
+		return err
+	}
+
+	err = checkRowsAffected(result, errNoRows)
+	if err == errNoRows {
+		return r.db.versionConflict(ctx, "products", id, domain.ErrProductNotFound.WithMetadata("id", id))
 	}
+	return err
+}
 
-	// Get total count
-	var total int32
-	err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM products").Scan(&total)
+// checkRowsAffected returns notFound when the statement did not touch any row.
+func checkRowsAffected(result sql.Result, notFound error) error {
+	n, err := result.RowsAffected()
 	if err != nil {
-		return nil, 0, err
+		return err
+	}
+	if n == 0 {
+		return notFound
 	}
+	return nil
+}
 
-	return products, total, nil
+func scanProduct(row rowScanner) (*domain.Product, error) {
+	product := &domain.Product{}
+	err := row.Scan(
+		&product.ID,
+		&product.Name,
+		&product.Description,
+		&product.Price,
+		&product.Stock,
+		&product.Reserved,
+		&product.CreatedAt,
+		&product.UpdatedAt,
+		&product.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return product, nil
 }
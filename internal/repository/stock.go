@@ -0,0 +1,444 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"go-backend-service/internal/domain"
+)
+
+type postgresStockRepository struct {
+	db tracedDB
+}
+
+func NewPostgresStockRepository(db *sql.DB) domain.StockRepository {
+	return &postgresStockRepository{db: tracedDB{db}}
+}
+
+// Every change to the stock or the reservations of a product first locks the
+// product row, so concurrent changes on the same product run one after the
+// other and each sees the stock left by the previous one. Each sets
+// products.updated_at, since reserved and available stock are part of the
+// product, but none bumps products.version: the etag guards the fields
+// UpdateProduct writes, and checkout traffic must not invalidate the etag of
+// an admin's edit.
+
+// Apply first expires the lapsed reservations of the product, like Reserve,
+// so that the stock they held can be sold or corrected away before the
+// sweeper runs.
+func (r *postgresStockRepository) Apply(ctx context.Context, movement *domain.StockMovement) (*domain.Product, error) {
+	var product *domain.Product
+	err := r.db.inTx(ctx, func(tx tracedTx) error {
+		if err := lockProduct(ctx, tx, movement.ProductID); err != nil {
+			return err
+		}
+		if _, err := expireReservations(ctx, tx, movement.ProductID, movement.CreatedAt); err != nil {
+			return err
+		}
+
+		var err error
+		product, err = scanProduct(tx.QueryRowContext(ctx, `
+            UPDATE products SET stock = stock + $2, updated_at = $3
+            WHERE id = $1 AND stock + $2 >= reserved
+            RETURNING `+productColumns,
+			movement.ProductID, movement.Quantity, movement.CreatedAt))
+		if err == sql.ErrNoRows {
+			return stockNotApplied(ctx, tx, movement.ProductID)
+		}
+		if err != nil {
+			return err
+		}
+
+		movement.StockAfter = product.Stock
+		return insertMovement(ctx, tx, movement)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+func insertMovement(ctx context.Context, tx tracedTx, movement *domain.StockMovement) error {
+	_, err := tx.ExecContext(ctx, `
+        INSERT INTO stock_movements (id, product_id, type, quantity, stock_after, reason, actor, reference, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+    `,
+		movement.ID,
+		movement.ProductID,
+		movement.Type,
+		movement.Quantity,
+		movement.StockAfter,
+		movement.Reason,
+		movement.Actor,
+		movement.Reference,
+		movement.CreatedAt,
+	)
+	return err
+}
+
+// stockNotApplied tells why a conditional stock UPDATE matched no row.
+func stockNotApplied(ctx context.Context, tx tracedTx, productID string) error {
+	var stock, reserved int32
+	err := tx.QueryRowContext(ctx, "SELECT stock, reserved FROM products WHERE id = $1", productID).Scan(&stock, &reserved)
+	if err == sql.ErrNoRows {
+		return domain.ErrProductNotFound.WithMetadata("id", productID)
+	}
+	if err != nil {
+		return err
+	}
+	return domain.ErrInsufficientStock.
+		WithMetadata("product_id", productID).
+		WithMetadata("stock", strconv.Itoa(int(stock))).
+		WithMetadata("available", strconv.Itoa(int(stock-reserved)))
+}
+
+// stockMovementListSpec lists the fields of ListStockMovements filters.
+var stockMovementListSpec = listSpec[*domain.StockMovement]{
+	fields: map[string]listField[*domain.StockMovement]{
+		"id":         {column: "id", kind: kindString, value: func(m *domain.StockMovement) any { return m.ID }},
+		"type":       {column: "type", kind: kindString, filterable: true},
+		"quantity":   {column: "quantity", kind: kindInteger, filterable: true},
+		"actor":      {column: "actor", kind: kindString, filterable: true},
+		"reference":  {column: "reference", kind: kindString, filterable: true},
+		"created_at": {column: "created_at", kind: kindTime, filterable: true, sortable: true, value: func(m *domain.StockMovement) any { return m.CreatedAt }},
+	},
+	defaultOrder: "created_at desc",
+	tiebreaker:   "id",
+}
+
+// ListMovements returns the movements of a product matching query.Filter,
+// newest first, or ErrProductNotFound when there is no such product.
+func (r *postgresStockRepository) ListMovements(ctx context.Context, productID string, query domain.ListQuery) ([]*domain.StockMovement, domain.PageResult, error) {
+	var result domain.PageResult
+
+	plan, err := newListPlan(stockMovementListSpec, query)
+	if err != nil {
+		return nil, result, err
+	}
+
+	var exists bool
+	err = r.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM products WHERE id = $1)", productID).Scan(&exists)
+	if err != nil {
+		return nil, result, err
+	}
+	if !exists {
+		return nil, result, domain.ErrProductNotFound.WithMetadata("id", productID)
+	}
+	plan.require("product_id", productID)
+	clauses, err := plan.pageClauses(query.Page)
+	if err != nil {
+		return nil, result, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT id, product_id, type, quantity, stock_after, reason, actor, reference, created_at
+        FROM stock_movements `+clauses, plan.args...)
+	if err != nil {
+		return nil, result, err
+	}
+	defer rows.Close()
+
+	var movements []*domain.StockMovement
+	for rows.Next() {
+		movement := &domain.StockMovement{}
+		err := rows.Scan(
+			&movement.ID,
+			&movement.ProductID,
+			&movement.Type,
+			&movement.Quantity,
+			&movement.StockAfter,
+			&movement.Reason,
+			&movement.Actor,
+			&movement.Reference,
+			&movement.CreatedAt,
+		)
+		if err != nil {
+			return nil, result, err
+		}
+		movements = append(movements, movement)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, result, err
+	}
+
+	// One extra row was fetched to tell whether there is a next page
+	if len(movements) > int(query.Page.Limit) {
+		movements = movements[:query.Page.Limit]
+		result.NextPageToken = plan.nextPageToken(movements[len(movements)-1])
+	}
+
+	if query.Page.IncludeTotal {
+		countPlan, _ := newListPlan(stockMovementListSpec, query)
+		countPlan.require("product_id", productID)
+		err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM stock_movements "+countPlan.where(), countPlan.args...).Scan(&result.Total)
+		if err != nil {
+			return nil, result, err
+		}
+	}
+
+	return movements, result, nil
+}
+
+const reservationColumns = "id, product_id, quantity, status, reference, actor, expires_at, created_at, updated_at"
+
+// expiredSweepBatch is how many products ReleaseExpired handles per call.
+const expiredSweepBatch = 100
+
+// Reserve first expires the lapsed reservations of the product, so that they
+// stop counting against its available stock even before the sweeper runs.
+func (r *postgresStockRepository) Reserve(ctx context.Context, reservation *domain.StockReservation) (*domain.Product, error) {
+	var product *domain.Product
+	err := r.db.inTx(ctx, func(tx tracedTx) error {
+		if err := lockProduct(ctx, tx, reservation.ProductID); err != nil {
+			return err
+		}
+		if _, err := expireReservations(ctx, tx, reservation.ProductID, reservation.CreatedAt); err != nil {
+			return err
+		}
+
+		var err error
+		product, err = scanProduct(tx.QueryRowContext(ctx, `
+            UPDATE products SET reserved = reserved + $2, updated_at = $3
+            WHERE id = $1 AND stock - reserved >= $2
+            RETURNING `+productColumns,
+			reservation.ProductID, reservation.Quantity, reservation.CreatedAt))
+		if err == sql.ErrNoRows {
+			return stockNotApplied(ctx, tx, reservation.ProductID)
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+            INSERT INTO stock_reservations (`+reservationColumns+`)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+        `,
+			reservation.ID,
+			reservation.ProductID,
+			reservation.Quantity,
+			reservation.Status,
+			reservation.Reference,
+			reservation.Actor,
+			reservation.ExpiresAt,
+			reservation.CreatedAt,
+			reservation.UpdatedAt,
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+func (r *postgresStockRepository) Commit(ctx context.Context, id string, movement *domain.StockMovement) (*domain.StockReservation, *domain.Product, error) {
+	var reservation *domain.StockReservation
+	var product *domain.Product
+	err := r.db.inTx(ctx, func(tx tracedTx) error {
+		var err error
+		reservation, err = lockReservation(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if reservation.Status != domain.ReservationActive {
+			return domain.ErrReservationNotActive.
+				WithMetadata("id", id).
+				WithMetadata("status", string(reservation.Status))
+		}
+		if !reservation.ExpiresAt.After(movement.CreatedAt) {
+			return domain.ErrReservationExpired.WithMetadata("id", id)
+		}
+
+		product, err = scanProduct(tx.QueryRowContext(ctx, `
+            UPDATE products SET stock = stock - $2, reserved = reserved - $2, updated_at = $3
+            WHERE id = $1
+            RETURNING `+productColumns,
+			reservation.ProductID, reservation.Quantity, movement.CreatedAt))
+		if err != nil {
+			return err
+		}
+		if err := setReservationStatus(ctx, tx, reservation, domain.ReservationCommitted, movement.CreatedAt); err != nil {
+			return err
+		}
+
+		movement.ProductID = reservation.ProductID
+		movement.Quantity = -reservation.Quantity
+		movement.StockAfter = product.Stock
+		return insertMovement(ctx, tx, movement)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return reservation, product, nil
+}
+
+func (r *postgresStockRepository) Release(ctx context.Context, id string, now time.Time) (*domain.StockReservation, *domain.Product, error) {
+	var reservation *domain.StockReservation
+	var product *domain.Product
+	err := r.db.inTx(ctx, func(tx tracedTx) error {
+		var err error
+		reservation, err = lockReservation(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		switch reservation.Status {
+		case domain.ReservationActive:
+			product, err = scanProduct(tx.QueryRowContext(ctx, `
+                UPDATE products SET reserved = reserved - $2, updated_at = $3
+                WHERE id = $1
+                RETURNING `+productColumns,
+				reservation.ProductID, reservation.Quantity, now))
+			if err != nil {
+				return err
+			}
+			return setReservationStatus(ctx, tx, reservation, domain.ReservationReleased, now)
+		case domain.ReservationReleased, domain.ReservationExpired:
+			product, err = scanProduct(tx.QueryRowContext(ctx,
+				"SELECT "+productColumns+" FROM products WHERE id = $1", reservation.ProductID))
+			return err
+		default:
+			return domain.ErrReservationNotActive.
+				WithMetadata("id", id).
+				WithMetadata("status", string(reservation.Status))
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return reservation, product, nil
+}
+
+// ReleaseExpired expires reservations product by product, each in its own
+// transaction so that a sweep never holds many product locks at once.
+func (r *postgresStockRepository) ReleaseExpired(ctx context.Context, now time.Time) (int, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT DISTINCT product_id FROM stock_reservations
+        WHERE status = 'active' AND expires_at <= $1
+        LIMIT $2
+    `, now, expiredSweepBatch)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var productIDs []string
+	for rows.Next() {
+		var productID string
+		if err := rows.Scan(&productID); err != nil {
+			return 0, err
+		}
+		productIDs = append(productIDs, productID)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for _, productID := range productIDs {
+		err := r.db.inTx(ctx, func(tx tracedTx) error {
+			if err := lockProduct(ctx, tx, productID); err != nil {
+				return err
+			}
+			n, err := expireReservations(ctx, tx, productID, now)
+			released += n
+			return err
+		})
+		if err != nil && !errors.Is(err, domain.ErrProductNotFound) {
+			return released, err
+		}
+	}
+	return released, nil
+}
+
+// lockProduct takes the row lock of a product for the rest of tx.
+func lockProduct(ctx context.Context, tx tracedTx, productID string) error {
+	var id string
+	err := tx.QueryRowContext(ctx, "SELECT id FROM products WHERE id = $1 FOR UPDATE", productID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return domain.ErrProductNotFound.WithMetadata("id", productID)
+	}
+	return err
+}
+
+// lockReservation locks the product of a reservation, then the reservation,
+// in the same order as Reserve so that the two cannot deadlock.
+func lockReservation(ctx context.Context, tx tracedTx, id string) (*domain.StockReservation, error) {
+	notFound := domain.ErrReservationNotFound.WithMetadata("id", id)
+
+	var productID string
+	err := tx.QueryRowContext(ctx, "SELECT product_id FROM stock_reservations WHERE id = $1", id).Scan(&productID)
+	if err == sql.ErrNoRows {
+		return nil, notFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := lockProduct(ctx, tx, productID); err != nil {
+		return nil, err
+	}
+
+	reservation, err := scanReservation(tx.QueryRowContext(ctx,
+		"SELECT "+reservationColumns+" FROM stock_reservations WHERE id = $1 FOR UPDATE", id))
+	if err == sql.ErrNoRows {
+		return nil, notFound
+	}
+	return reservation, err
+}
+
+// expireReservations marks the lapsed active reservations of a locked
+// product as expired and returns their stock.
+func expireReservations(ctx context.Context, tx tracedTx, productID string, now time.Time) (int, error) {
+	var count int
+	var quantity int64
+	err := tx.QueryRowContext(ctx, `
+        WITH expired AS (
+            UPDATE stock_reservations SET status = 'expired', updated_at = $2
+            WHERE product_id = $1 AND status = 'active' AND expires_at <= $2
+            RETURNING quantity
+        )
+        SELECT COUNT(*), COALESCE(SUM(quantity), 0) FROM expired
+    `, productID, now).Scan(&count, &quantity)
+	if err != nil || count == 0 {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE products SET reserved = reserved - $2, updated_at = $3 WHERE id = $1", productID, quantity, now)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func setReservationStatus(ctx context.Context, tx tracedTx, reservation *domain.StockReservation, status domain.ReservationStatus, now time.Time) error {
+	_, err := tx.ExecContext(ctx, "UPDATE stock_reservations SET status = $2, updated_at = $3 WHERE id = $1",
+		reservation.ID, status, now)
+	if err != nil {
+		return err
+	}
+	reservation.Status = status
+	reservation.UpdatedAt = now
+	return nil
+}
+
+func scanReservation(row rowScanner) (*domain.StockReservation, error) {
+	reservation := &domain.StockReservation{}
+	err := row.Scan(
+		&reservation.ID,
+		&reservation.ProductID,
+		&reservation.Quantity,
+		&reservation.Status,
+		&reservation.Reference,
+		&reservation.Actor,
+		&reservation.ExpiresAt,
+		&reservation.CreatedAt,
+		&reservation.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
@@ -0,0 +1,54 @@
+// Package requestid carries the correlation id of a request through
+// context.Context.
+package requestid
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Header is the HTTP header carrying the request id.
+	Header = "X-Request-ID"
+	// MetadataKey is the gRPC metadata key carrying the request id.
+	MetadataKey = "x-request-id"
+)
+
+// maxLength bounds ids accepted from callers.
+const maxLength = 128
+
+type contextKey struct{}
+
+// New generates a request id.
+func New() string {
+	return uuid.NewString()
+}
+
+// Valid reports whether id, received from a caller, is safe to reuse: not
+// empty, at most 128 characters of letters, digits and -_.:
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// NewContext returns a copy of ctx carrying id.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request id stored in ctx, or "" when there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
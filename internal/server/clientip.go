@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"net"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// The gateway marks every call with the address of its HTTP client and a
+// token generated at startup. The gRPC server strips both keys from every
+// incoming call and trusts the address only when the token matches, so
+// other callers cannot pick the IP they are rate limited by.
+const (
+	gatewayClientIPMetadataKey = "x-gateway-client-ip"
+	gatewayTokenMetadataKey    = "x-gateway-token"
+)
+
+// gatewayToken is shared by the gateway and the gRPC server of this process.
+var gatewayToken = rand.Text()
+
+type clientIPKey struct{}
+
+// gatewayClientMetadata forwards the address of the HTTP client to the gRPC
+// server. Use it with runtime.WithMetadata.
+func gatewayClientMetadata(_ context.Context, r *http.Request) metadata.MD {
+	return metadata.Pairs(
+		gatewayClientIPMetadataKey, hostOnly(r.RemoteAddr),
+		gatewayTokenMetadataKey, gatewayToken,
+	)
+}
+
+// gatewayClientUnaryInterceptor removes the gateway marks from the incoming
+// metadata and stores the client address of calls the gateway marked in the
+// context. It runs first, so that the token is never logged.
+func gatewayClientUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return handler(gatewayClientContext(ctx), req)
+}
+
+// gatewayClientStreamInterceptor is the streaming counterpart of
+// gatewayClientUnaryInterceptor.
+func gatewayClientStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &contextServerStream{ServerStream: ss, ctx: gatewayClientContext(ss.Context())})
+}
+
+func gatewayClientContext(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+	ips := md.Get(gatewayClientIPMetadataKey)
+	tokens := md.Get(gatewayTokenMetadataKey)
+	if len(ips) == 0 && len(tokens) == 0 {
+		return ctx
+	}
+
+	md = md.Copy()
+	md.Delete(gatewayClientIPMetadataKey)
+	md.Delete(gatewayTokenMetadataKey)
+	ctx = metadata.NewIncomingContext(ctx, md)
+	if len(ips) == 1 && len(tokens) == 1 && subtle.ConstantTimeCompare([]byte(tokens[0]), []byte(gatewayToken)) == 1 {
+		ctx = context.WithValue(ctx, clientIPKey{}, ips[0])
+	}
+	return ctx
+}
+
+// rpcClientIP returns the address of the HTTP client for calls marked by the
+// gateway and the peer address of the RPC otherwise.
+func rpcClientIP(ctx context.Context) string {
+	if ip, ok := ctx.Value(clientIPKey{}).(string); ok {
+		return ip
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	return hostOnly(p.Addr.String())
+}
+
+func hostOnly(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
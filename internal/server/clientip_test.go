@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestRPCClientIP(t *testing.T) {
+	r := httptest.NewRequest("GET", "/v1/products", nil)
+	r.RemoteAddr = "203.0.113.7:51000"
+	marked := gatewayClientMetadata(context.Background(), r)
+
+	tests := []struct {
+		name string
+		peer string
+		md   metadata.MD
+		want string
+	}{
+		{"direct caller", "198.51.100.2", nil, "198.51.100.2"},
+		{"marked by the gateway", "127.0.0.1", marked, "203.0.113.7"},
+		{"gateway on another address", "172.18.0.5", marked, "203.0.113.7"},
+		{"loopback with x-forwarded-for", "127.0.0.1", metadata.Pairs("x-forwarded-for", "203.0.113.7"), "127.0.0.1"},
+		{"forged token", "127.0.0.1", metadata.Pairs(
+			gatewayClientIPMetadataKey, "203.0.113.7",
+			gatewayTokenMetadataKey, "guess",
+		), "127.0.0.1"},
+		{"missing token", "198.51.100.2", metadata.Pairs(gatewayClientIPMetadataKey, "203.0.113.7"), "198.51.100.2"},
+		{"two addresses", "127.0.0.1", metadata.Join(marked, metadata.Pairs(gatewayClientIPMetadataKey, "192.0.2.1")), "127.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(tt.peer), Port: 40000}})
+			if tt.md != nil {
+				ctx = metadata.NewIncomingContext(ctx, tt.md)
+			}
+
+			var got string
+			handler := func(ctx context.Context, req any) (any, error) {
+				got = rpcClientIP(ctx)
+				return nil, nil
+			}
+			if _, err := gatewayClientUnaryInterceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("rpcClientIP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGatewayClientInterceptorStripsMarks(t *testing.T) {
+	md := metadata.Pairs(
+		gatewayClientIPMetadataKey, "203.0.113.7",
+		gatewayTokenMetadataKey, gatewayToken,
+		"x-request-id", "req-42",
+	)
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		got, _ := metadata.FromIncomingContext(ctx)
+		for _, key := range []string{gatewayClientIPMetadataKey, gatewayTokenMetadataKey} {
+			if values := got.Get(key); len(values) != 0 {
+				t.Errorf("%s = %v reached the handler", key, values)
+			}
+		}
+		if id := got.Get("x-request-id"); len(id) != 1 || id[0] != "req-42" {
+			t.Errorf("x-request-id = %v, want [req-42]", id)
+		}
+		return nil, nil
+	}
+	if _, err := gatewayClientUnaryInterceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler); err != nil {
+		t.Fatal(err)
+	}
+	if len(md.Get(gatewayTokenMetadataKey)) != 1 {
+		t.Error("the caller's metadata was modified")
+	}
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"go-backend-service/internal/domain"
+	"go-backend-service/internal/logging"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain is reported as google.rpc.ErrorInfo.domain.
+const errorDomain = "go-backend-service"
+
+// errorCodes maps domain error kinds to gRPC codes. The gateway turns these
+// into HTTP statuses (404, 409, 400, 401, 403) via runtime.HTTPStatusFromCode;
+// Aborted is 409 too, or 412 for If-Match requests (gatewayErrorHandler).
+var errorCodes = []struct {
+	kind error
+	code codes.Code
+}{
+	{domain.ErrNotFound, codes.NotFound},
+	{domain.ErrConflict, codes.AlreadyExists},
+	{domain.ErrInvalidArgument, codes.InvalidArgument},
+	{domain.ErrFailedPrecondition, codes.FailedPrecondition},
+	{domain.ErrUnauthenticated, codes.Unauthenticated},
+	{domain.ErrPermissionDenied, codes.PermissionDenied},
+	{domain.ErrAborted, codes.Aborted},
+	{context.Canceled, codes.Canceled},
+	{context.DeadlineExceeded, codes.DeadlineExceeded},
+}
+
+// errorUnaryInterceptor converts errors returned by handlers into gRPC status errors.
+func errorUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return nil, toStatusError(ctx, err)
+	}
+	return resp, nil
+}
+
+// errorStreamInterceptor is the streaming counterpart of errorUnaryInterceptor.
+func errorStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := handler(srv, ss); err != nil {
+		return toStatusError(ss.Context(), err)
+	}
+	return nil
+}
+
+// toStatusError maps err to a status error. Errors that already carry a
+// status are returned unchanged; unknown errors become Internal and are
+// logged instead of being exposed to the caller.
+func toStatusError(ctx context.Context, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	code := codes.Internal
+	for _, m := range errorCodes {
+		if errors.Is(err, m.kind) {
+			code = m.code
+			break
+		}
+	}
+	if code == codes.Internal {
+		logging.FromContext(ctx).Error("internal error", "error", err)
+		return status.Error(codes.Internal, "internal error")
+	}
+
+	st := status.New(code, err.Error())
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		withDetails, detailErr := st.WithDetails(&errdetails.ErrorInfo{
+			Reason:   domainErr.Reason,
+			Domain:   errorDomain,
+			Metadata: domainErr.Metadata,
+		})
+		if detailErr == nil {
+			st = withDetails
+		}
+	}
+	return st.Err()
+}
@@ -1,28 +1,197 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
-	"log"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode"
 
+	delivery "go-backend-service/internal/delivery/grpc"
+	"go-backend-service/internal/domain"
 	"go-backend-service/pkg/pb"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
 )
 
-func StartHTTPGateway(ctx context.Context, mux *runtime.ServeMux, db *sql.DB, grpcAddr string) {
-	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+// NewGatewayMux creates the gRPC-Gateway mux. It forwards the request id and
+// the client address to the gRPC server as metadata and reports matched
+// routes to MetricsMiddleware and the HTTP span. The Authorization header is
+// forwarded as authorization metadata by the gateway itself, X-Api-Key as
+// x-api-key and If-Match as if-match. Rate limit and etag metadata from the
+// server are returned as RateLimit-*, Retry-After and ETag headers. PATCH
+// requests without an update mask only update the fields in their body.
+func NewGatewayMux(opts ...runtime.ServeMuxOption) *runtime.ServeMux {
+	opts = append([]runtime.ServeMuxOption{
+		runtime.WithMetadata(gatewayRequestIDMetadata),
+		runtime.WithMetadata(gatewayClientMetadata),
+		runtime.WithIncomingHeaderMatcher(gatewayHeaderMatcher),
+		runtime.WithOutgoingHeaderMatcher(gatewayOutgoingHeaderMatcher),
+		runtime.WithErrorHandler(gatewayErrorHandler),
+		runtime.WithMiddlewares(gatewayRouteMiddleware, gatewayPatchMiddleware),
+	}, opts...)
+	return runtime.NewServeMux(opts...)
+}
+
+// gatewayPatchMiddleware gives a PATCH request without an update mask the
+// mask of the fields in its JSON body. The gateway only derives the mask
+// itself when the body is bound to a single message field, while
+// UpdateProduct binds the whole request with body "*".
+func gatewayPatchMiddleware(next runtime.HandlerFunc) runtime.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		if r.Method == http.MethodPatch {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, "read request body", http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(withUpdateMask(body, pathParams)))
+		}
+		next(w, r, pathParams)
+	}
+}
+
+// withUpdateMask adds an updateMask with the other fields of a JSON object
+// body, leaving out path parameters and the etag. Bodies that already have
+// a mask, have no fields or are not JSON objects are returned unchanged.
+func withUpdateMask(body []byte, pathParams map[string]string) []byte {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return body
+	}
+	if _, ok := fields["updateMask"]; ok {
+		return body
+	}
+	if _, ok := fields["update_mask"]; ok {
+		return body
+	}
+
+	var paths []string
+	for name := range fields {
+		path := jsonToProtoName(name)
+		if _, ok := pathParams[path]; ok || path == "etag" {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	if len(paths) == 0 {
+		return body
+	}
+	sort.Strings(paths)
+
+	mask, err := json.Marshal(strings.Join(paths, ","))
+	if err != nil {
+		return body
+	}
+	fields["updateMask"] = mask
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return body
+	}
+	return out
+}
+
+// jsonToProtoName turns a JSON name such as updateMask into the proto field
+// name update_mask. Proto names are returned unchanged.
+func jsonToProtoName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsUpper(r) {
+			b.WriteByte('_')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// gatewayHeaderMatcher forwards X-Api-Key and If-Match in addition to the
+// headers the gateway forwards by default.
+func gatewayHeaderMatcher(key string) (string, bool) {
+	switch {
+	case strings.EqualFold(key, "X-Api-Key"):
+		return apiKeyMetadataKey, true
+	case strings.EqualFold(key, "If-Match"):
+		return delivery.IfMatchMetadataKey, true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// gatewayOutgoingHeaderMatcher returns rate limit metadata as plain HTTP
+// headers and everything else with the default Grpc-Metadata- prefix.
+func gatewayOutgoingHeaderMatcher(key string) (string, bool) {
+	if header, ok := rateLimitHeaders[key]; ok {
+		return header, true
+	}
+	if key == delivery.ETagMetadataKey {
+		return "ETag", true
+	}
+	return runtime.MetadataHeaderPrefix + key, true
+}
+
+// gatewayErrorHandler answers an etag mismatch with 412 Precondition Failed
+// instead of 409 when the etag came from an If-Match header, as HTTP
+// clients expect.
+func gatewayErrorHandler(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	if r.Header.Get("If-Match") != "" && errorReason(err) == domain.ErrVersionMismatch.Reason {
+		w = preconditionFailedWriter{w}
+	}
+	runtime.DefaultHTTPErrorHandler(ctx, mux, marshaler, w, r, err)
+}
+
+// preconditionFailedWriter turns a 409 status into 412.
+type preconditionFailedWriter struct {
+	http.ResponseWriter
+}
+
+func (w preconditionFailedWriter) WriteHeader(code int) {
+	if code == http.StatusConflict {
+		code = http.StatusPreconditionFailed
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// errorReason returns the google.rpc.ErrorInfo reason of a status error.
+func errorReason(err error) string {
+	st, _ := status.FromError(err)
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info.Reason
+		}
+	}
+	return ""
+}
+
+func StartHTTPGateway(ctx context.Context, mux *runtime.ServeMux, db *sql.DB, grpcAddr string, creds credentials.TransportCredentials) error {
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		// Injects the W3C trace context of the HTTP span into gRPC metadata
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	}
 
 	// Register all handlers
 	if err := pb.RegisterProductServiceHandlerFromEndpoint(ctx, mux, grpcAddr, opts); err != nil {
-		log.Fatalf("Failed to register product gateway: %v", err)
+		return fmt.Errorf("register product gateway: %w", err)
 	}
 
 	if err := pb.RegisterDipanTypeServiceHandlerFromEndpoint(ctx, mux, grpcAddr, opts); err != nil {
-		log.Fatalf("Failed to register dipan gateway: %v", err)
+		return fmt.Errorf("register dipan gateway: %w", err)
+	}
+
+	if err := pb.RegisterApiKeyServiceHandlerFromEndpoint(ctx, mux, grpcAddr, opts); err != nil {
+		return fmt.Errorf("register api key gateway: %w", err)
 	}
 
 	// Tambahkan handler service lainnya di sini
+	return nil
 }
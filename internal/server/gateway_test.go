@@ -0,0 +1,182 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	delivery "go-backend-service/internal/delivery/grpc"
+	"go-backend-service/internal/domain"
+	"go-backend-service/pkg/pb"
+)
+
+const testProductID = "550e8400-e29b-41d4-a716-446655440000"
+
+// versionedProducts holds one product at version, enough for the etag
+// checks of DeleteProduct.
+type versionedProducts struct {
+	domain.ProductUsecase
+	version int64
+}
+
+func (u versionedProducts) GetProduct(ctx context.Context, id string) (*domain.Product, error) {
+	return &domain.Product{ID: id, Version: u.version}, nil
+}
+
+func (u versionedProducts) DeleteProduct(ctx context.Context, id string, version int64) error {
+	if version != 0 && version != u.version {
+		return domain.ErrVersionMismatch
+	}
+	return nil
+}
+
+// statusProductServer maps handler errors like errorUnaryInterceptor does,
+// which the gateway's in-process registration skips.
+type statusProductServer struct {
+	*delivery.ProductHandler
+}
+
+func (s statusProductServer) DeleteProduct(ctx context.Context, req *pb.DeleteProductRequest) (*pb.DeleteProductResponse, error) {
+	resp, err := s.ProductHandler.DeleteProduct(ctx, req)
+	return resp, toStatusError(ctx, err)
+}
+
+func TestGatewayIfMatch(t *testing.T) {
+	gateway := NewGatewayMux()
+	handler := delivery.NewProductHandler(versionedProducts{version: 3})
+	if err := pb.RegisterProductServiceHandlerServer(context.Background(), gateway, statusProductServer{handler}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		ifMatch []string
+		want    int
+	}{
+		{name: "unconditional", want: http.StatusOK},
+		{name: "matching", ifMatch: []string{`"3"`}, want: http.StatusOK},
+		{name: "stale", ifMatch: []string{`"2"`}, want: http.StatusPreconditionFailed},
+		{name: "weak", ifMatch: []string{`W/"3"`}, want: http.StatusPreconditionFailed},
+		{name: "star", ifMatch: []string{"*"}, want: http.StatusOK},
+		{name: "list", ifMatch: []string{`"2", "3"`}, want: http.StatusOK},
+		{name: "list not matching", ifMatch: []string{`"1", "2"`}, want: http.StatusPreconditionFailed},
+		{name: "repeated header", ifMatch: []string{`"2"`, `"3"`}, want: http.StatusOK},
+		{name: "malformed", ifMatch: []string{"three"}, want: http.StatusBadRequest},
+		{name: "stale field", query: "?etag=2", want: http.StatusConflict},
+		{name: "weak field", query: `?etag=W/"3"`, want: http.StatusConflict},
+		{name: "field wins over header", query: "?etag=3", ifMatch: []string{`"2"`}, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodDelete, "/v1/products/"+testProductID+tt.query, nil)
+			for _, value := range tt.ifMatch {
+				r.Header.Add("If-Match", value)
+			}
+			w := httptest.NewRecorder()
+			gateway.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func TestWithUpdateMask(t *testing.T) {
+	idParam := map[string]string{"id": testProductID}
+	tests := []struct {
+		name       string
+		body       string
+		pathParams map[string]string
+		want       string
+	}{
+		{"subset", `{"price":1250000}`, idParam, `{"price":1250000,"updateMask":"price"}`},
+		{"sorted paths", `{"price":1,"name":"Meja","description":"kayu"}`, idParam,
+			`{"description":"kayu","name":"Meja","price":1,"updateMask":"description,name,price"}`},
+		{"camelCase key", `{"namaType":"Jati"}`, nil, `{"namaType":"Jati","updateMask":"nama_type"}`},
+		{"snake_case key", `{"nama_type":"Jati"}`, nil, `{"nama_type":"Jati","updateMask":"nama_type"}`},
+		{"path param left out", `{"id":"` + testProductID + `","name":"Meja"}`, idParam,
+			`{"id":"` + testProductID + `","name":"Meja","updateMask":"name"}`},
+		{"etag left out", `{"etag":"\"3\"","name":"Meja"}`, idParam, `{"etag":"\"3\"","name":"Meja","updateMask":"name"}`},
+		{"updateMask kept", `{"name":"Meja","updateMask":"price"}`, idParam, `{"name":"Meja","updateMask":"price"}`},
+		{"update_mask kept", `{"name":"Meja","update_mask":"price"}`, idParam, `{"name":"Meja","update_mask":"price"}`},
+		{"empty body", ``, idParam, ``},
+		{"empty object", `{}`, idParam, `{}`},
+		{"only path params and etag", `{"id":"x","etag":"\"3\""}`, idParam, `{"id":"x","etag":"\"3\""}`},
+		{"array", `[{"name":"Meja"}]`, idParam, `[{"name":"Meja"}]`},
+		{"string", `"name"`, idParam, `"name"`},
+		{"null", `null`, idParam, `null`},
+		{"invalid JSON", `{"name":`, idParam, `{"name":`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(withUpdateMask([]byte(tt.body), tt.pathParams)); got != tt.want {
+				t.Errorf("withUpdateMask(%s) = %s, want %s", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONToProtoName(t *testing.T) {
+	tests := map[string]string{
+		"name":       "name",
+		"updateMask": "update_mask",
+		"createdAt":  "created_at",
+		"pageToken":  "page_token",
+		"page_token": "page_token",
+		"":           "",
+	}
+	for in, want := range tests {
+		if got := jsonToProtoName(in); got != want {
+			t.Errorf("jsonToProtoName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// maskRecorder records the fields UpdateProduct is called with.
+type maskRecorder struct {
+	domain.ProductUsecase
+	fields *[]string
+}
+
+func (u maskRecorder) UpdateProduct(ctx context.Context, product *domain.Product, fields []string) error {
+	*u.fields = fields
+	return nil
+}
+
+func TestGatewayPatchDerivesUpdateMask(t *testing.T) {
+	var fields []string
+	gateway := NewGatewayMux()
+	handler := delivery.NewProductHandler(maskRecorder{fields: &fields})
+	if err := pb.RegisterProductServiceHandlerServer(context.Background(), gateway, handler); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		method string
+		body   string
+		want   []string
+	}{
+		{http.MethodPatch, `{"price":1250000}`, []string{"price"}},
+		{http.MethodPatch, `{"name":"Meja","description":"kayu"}`, []string{"description", "name"}},
+		{http.MethodPatch, `{"name":"Meja","updateMask":"name,price"}`, []string{"name", "price"}},
+		// PUT replaces the product; the usecase picks the default fields.
+		{http.MethodPut, `{"name":"Meja","price":1}`, nil},
+	}
+	for _, tt := range tests {
+		fields = nil
+		r := httptest.NewRequest(tt.method, "/v1/products/"+testProductID, strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+		gateway.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s %s: status %d: %s", tt.method, tt.body, w.Code, w.Body)
+			continue
+		}
+		if !reflect.DeepEqual(fields, tt.want) {
+			t.Errorf("%s %s: fields = %q, want %q", tt.method, tt.body, fields, tt.want)
+		}
+	}
+}
@@ -1,44 +1,132 @@
 package server
 
 import (
+	"context"
 	"database/sql"
-	"log"
+	"log/slog"
 	"net"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 
+	"go-backend-service/internal/auth"
 	delivery "go-backend-service/internal/delivery/grpc"
+	"go-backend-service/internal/health"
+	"go-backend-service/internal/metrics"
 	"go-backend-service/internal/repository"
 	"go-backend-service/internal/usecase"
 	"go-backend-service/pkg/pb"
 )
 
-func StartGRPCServer(db *sql.DB, port string) {
+// NewGRPCServer creates the gRPC server with interceptors and all services
+// registered. A nil authn disables authentication and ApiKeyService, a nil
+// policy disables authorization and a nil limiter disables rate limiting.
+// opts are appended to the default server options.
+func NewGRPCServer(db *sql.DB, checker *health.Checker, logger *slog.Logger, m *metrics.Metrics, authn *auth.Authenticator, policy *auth.Policy, limiter *RateLimiter, opts ...grpc.ServerOption) *grpc.Server {
+	unary := []grpc.UnaryServerInterceptor{
+		gatewayClientUnaryInterceptor,
+		requestIDUnaryInterceptor,
+		loggingUnaryInterceptor(logger),
+		metricsUnaryInterceptor(m),
+		recoveryUnaryInterceptor,
+		errorUnaryInterceptor,
+	}
+	stream := []grpc.StreamServerInterceptor{
+		gatewayClientStreamInterceptor,
+		requestIDStreamInterceptor,
+		loggingStreamInterceptor(logger),
+		metricsStreamInterceptor(m),
+		recoveryStreamInterceptor,
+		errorStreamInterceptor,
+	}
+	if limiter != nil {
+		unary = append(unary, ipRateLimitUnaryInterceptor(limiter))
+		stream = append(stream, ipRateLimitStreamInterceptor(limiter))
+	}
+	if authn != nil {
+		unary = append(unary, authUnaryInterceptor(authn))
+		stream = append(stream, authStreamInterceptor(authn))
+		if policy != nil {
+			unary = append(unary, authzUnaryInterceptor(policy))
+			stream = append(stream, authzStreamInterceptor(policy))
+		}
+	}
+	if limiter != nil {
+		unary = append(unary, rateLimitUnaryInterceptor(limiter))
+		stream = append(stream, rateLimitStreamInterceptor(limiter))
+	}
+	unary = append(unary, validationUnaryInterceptor)
+	stream = append(stream, validationStreamInterceptor)
+
+	opts = append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}, opts...)
+	opts = append(opts, grpc.StatsHandler(otelgrpc.NewServerHandler()))
+	grpcServer := grpc.NewServer(opts...)
+
+	// Register all services
+	registerServices(grpcServer, db, checker, authn != nil)
+
+	return grpcServer
+}
+
+// StartGRPCServer listens on port and serves until grpcServer is stopped.
+func StartGRPCServer(grpcServer *grpc.Server, port string) error {
 	lis, err := net.Listen("tcp", port)
 	if err != nil {
-		log.Fatalf("Failed to listen: %v", err)
+		return err
 	}
 
-	grpcServer := grpc.NewServer()
+	slog.Info("gRPC server listening", "addr", port)
+	return grpcServer.Serve(lis)
+}
 
-	// Register all services
-	registerServices(grpcServer, db)
+// StopGRPCServer stops grpcServer gracefully, waiting for in-flight RPCs to
+// finish. If ctx is done first, remaining RPCs are cancelled.
+func StopGRPCServer(ctx context.Context, grpcServer *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
 
-	log.Printf("gRPC server listening at %s", port)
-	if err := grpcServer.Serve(lis); err != nil {
-		log.Fatalf("Failed to serve gRPC: %v", err)
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		slog.Warn("gRPC graceful stop timed out, forcing stop")
+		grpcServer.Stop()
 	}
 }
-func registerServices(server *grpc.Server, db *sql.DB) {
+
+// registerServices registers ApiKeyService only with authentication, since
+// anyone could otherwise mint keys that stay valid once it is enabled.
+func registerServices(server *grpc.Server, db *sql.DB, checker *health.Checker, withAuth bool) {
 	// Product
 	productRepo := repository.NewPostgresProductRepository(db)
-	productUsecase := usecase.NewProductUsecase(productRepo)
+	stockRepo := repository.NewPostgresStockRepository(db)
+	productUsecase := usecase.NewProductUsecase(productRepo, stockRepo)
 	productHandler := delivery.NewProductHandler(productUsecase)
 	pb.RegisterProductServiceServer(server, productHandler)
+	checker.AddService(pb.ProductService_ServiceDesc.ServiceName)
 
 	// DipanType
 	dipanRepo := repository.NewPostgresDipanTypeRepository(db)
 	dipanUsecase := usecase.NewDipanTypeUsecase(dipanRepo)
 	dipanHandler := delivery.NewDipanTypeHandler(dipanUsecase)
 	pb.RegisterDipanTypeServiceServer(server, dipanHandler)
+	checker.AddService(pb.DipanTypeService_ServiceDesc.ServiceName)
+
+	// ApiKey
+	if withAuth {
+		apiKeyRepo := repository.NewPostgresAPIKeyRepository(db)
+		apiKeyUsecase := usecase.NewAPIKeyUsecase(apiKeyRepo)
+		apiKeyHandler := delivery.NewAPIKeyHandler(apiKeyUsecase)
+		pb.RegisterApiKeyServiceServer(server, apiKeyHandler)
+		checker.AddService(pb.ApiKeyService_ServiceDesc.ServiceName)
+	}
+
+	// Health (grpc.health.v1)
+	healthpb.RegisterHealthServer(server, checker.HealthServer())
 }
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go-backend-service/internal/logging"
+	"go-backend-service/internal/redact"
+	"go-backend-service/internal/requestid"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// loggingUnaryInterceptor stores a request-scoped logger in the context and
+// logs every RPC with its code and latency, plus its redacted metadata at
+// debug level. It expects requestIDUnaryInterceptor to run first.
+func loggingUnaryInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, reqLogger := rpcLogger(ctx, logger, info.FullMethod)
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logRPC(ctx, reqLogger, start, err)
+		return resp, err
+	}
+}
+
+// loggingStreamInterceptor is the streaming counterpart of loggingUnaryInterceptor.
+func loggingStreamInterceptor(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, reqLogger := rpcLogger(ss.Context(), logger, info.FullMethod)
+
+		start := time.Now()
+		err := handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+		logRPC(ctx, reqLogger, start, err)
+		return err
+	}
+}
+
+func rpcLogger(ctx context.Context, logger *slog.Logger, method string) (context.Context, *slog.Logger) {
+	attrs := []any{"request_id", requestid.FromContext(ctx), "grpc.method", method}
+	if p, ok := peer.FromContext(ctx); ok {
+		attrs = append(attrs, "peer", p.Addr.String())
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		attrs = append(attrs, "trace_id", sc.TraceID().String())
+	}
+	reqLogger := logger.With(attrs...)
+	return logging.WithContext(ctx, reqLogger), reqLogger
+}
+
+func logRPC(ctx context.Context, logger *slog.Logger, start time.Time, err error) {
+	code := status.Code(err)
+	level := slog.LevelInfo
+	switch code {
+	case codes.Unknown, codes.Internal, codes.DataLoss, codes.Unavailable, codes.Unimplemented:
+		level = slog.LevelError
+	}
+
+	attrs := []any{"grpc.code", code.String(), "latency_ms", latencyMillis(start)}
+	if err != nil {
+		attrs = append(attrs, "error", status.Convert(err).Message())
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok && logger.Enabled(ctx, slog.LevelDebug) {
+		attrs = append(attrs, "grpc.metadata", redact.Headers(md))
+	}
+	logger.Log(ctx, level, "rpc finished", attrs...)
+}
+
+func latencyMillis(start time.Time) float64 {
+	return float64(time.Since(start).Microseconds()) / 1000
+}
+
+// contextServerStream overrides the context of a grpc.ServerStream.
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// LoggingMiddleware logs every HTTP request, with its redacted headers at
+// debug level, and stores a request-scoped logger in the request context.
+// It expects RequestIDMiddleware to run first.
+func LoggingMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			reqLogger := logger.With(
+				"request_id", requestid.FromContext(ctx),
+				"http.method", r.Method,
+				"http.path", r.URL.Path,
+				"peer", r.RemoteAddr,
+			)
+			if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+				reqLogger = reqLogger.With("trace_id", sc.TraceID().String())
+			}
+			ctx = logging.WithContext(ctx, reqLogger)
+
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			start := time.Now()
+			next.ServeHTTP(rec, r.WithContext(ctx))
+
+			level := slog.LevelInfo
+			if rec.status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			attrs := []any{
+				"http.status", rec.status,
+				"http.bytes", rec.bytes,
+				"user_agent", r.UserAgent(),
+				"latency_ms", latencyMillis(start),
+			}
+			if reqLogger.Enabled(ctx, slog.LevelDebug) {
+				attrs = append(attrs, "http.headers", redact.Headers(r.Header))
+			}
+			reqLogger.Log(ctx, level, "http request finished", attrs...)
+		})
+	}
+}
+
+// statusRecorder captures the status code and size of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.status = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer (Flush).
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
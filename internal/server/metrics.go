@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-backend-service/internal/metrics"
+
+	gorilla "github.com/gorilla/mux"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// metricsUnaryInterceptor records the count and latency of every RPC.
+func metricsUnaryInterceptor(m *metrics.Metrics) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.ObserveRPC(info.FullMethod, status.Code(err).String(), time.Since(start))
+		return resp, err
+	}
+}
+
+// metricsStreamInterceptor is the streaming counterpart of metricsUnaryInterceptor.
+func metricsStreamInterceptor(m *metrics.Metrics) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		m.ObserveRPC(info.FullMethod, status.Code(err).String(), time.Since(start))
+		return err
+	}
+}
+
+// routeKey holds a *string in the request context that the gateway fills in
+// with the matched path template.
+type routeKey struct{}
+
+// MetricsMiddleware records the count and latency of every HTTP request,
+// labelled by the gorilla route template, or by the gateway path template for
+// API calls.
+func MetricsMiddleware(m *metrics.Metrics) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := "unmatched"
+			if current := gorilla.CurrentRoute(r); current != nil {
+				if tmpl, err := current.GetPathTemplate(); err == nil {
+					route = tmpl
+				}
+			}
+			ctx := context.WithValue(r.Context(), routeKey{}, &route)
+
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			start := time.Now()
+			next.ServeHTTP(rec, r.WithContext(ctx))
+			m.ObserveHTTP(r.Method, route, strconv.Itoa(rec.status), time.Since(start))
+		})
+	}
+}
+
+// gatewayRouteMiddleware reports the matched gateway path template to
+// MetricsMiddleware and names the HTTP span after it. Use it with
+// runtime.WithMiddlewares.
+func gatewayRouteMiddleware(next runtime.HandlerFunc) runtime.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		if pattern, ok := runtime.HTTPPattern(r.Context()); ok {
+			// The pattern prints single-segment variables as {id=*}.
+			template := strings.ReplaceAll(pattern.String(), "=*}", "}")
+			if route, ok := r.Context().Value(routeKey{}).(*string); ok {
+				*route = template
+			}
+			span := trace.SpanFromContext(r.Context())
+			span.SetName(r.Method + " " + template)
+			span.SetAttributes(semconv.HTTPRoute(template))
+		}
+		next(w, r, pathParams)
+	}
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-backend-service/internal/metrics"
+	"go-backend-service/pkg/pb"
+
+	gorilla "github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMetricsUnaryInterceptor(t *testing.T) {
+	m := metrics.New(nil)
+	interceptor := metricsUnaryInterceptor(m)
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/pb.ProductService/GetProduct"}
+	ok := func(ctx context.Context, req any) (any, error) { return nil, nil }
+	notFound := func(ctx context.Context, req any) (any, error) {
+		return nil, status.Error(codes.NotFound, "product not found")
+	}
+	for _, handler := range []grpc.UnaryHandler{ok, ok, notFound} {
+		_, _ = interceptor(context.Background(), nil, info, handler)
+	}
+
+	want := `
+# HELP grpc_server_handled_total Number of RPCs completed on the server, by method and code.
+# TYPE grpc_server_handled_total counter
+grpc_server_handled_total{grpc_code="NotFound",grpc_method="GetProduct",grpc_service="pb.ProductService"} 1
+grpc_server_handled_total{grpc_code="OK",grpc_method="GetProduct",grpc_service="pb.ProductService"} 2
+`
+	if err := testutil.GatherAndCompare(m.Gatherer(), strings.NewReader(want), "grpc_server_handled_total"); err != nil {
+		t.Error(err)
+	}
+
+	labels := map[string]string{"grpc_service": "pb.ProductService", "grpc_method": "GetProduct"}
+	if got := histogramCount(t, m.Gatherer(), "grpc_server_handling_seconds", withLabel(labels, "grpc_code", "OK")); got != 2 {
+		t.Errorf("OK latency samples = %d, want 2", got)
+	}
+	if got := histogramCount(t, m.Gatherer(), "grpc_server_handling_seconds", withLabel(labels, "grpc_code", "NotFound")); got != 1 {
+		t.Errorf("NotFound latency samples = %d, want 1", got)
+	}
+}
+
+// testProductServer answers GetProduct for the gateway routes.
+type testProductServer struct {
+	pb.UnimplementedProductServiceServer
+}
+
+func (testProductServer) GetProduct(ctx context.Context, req *pb.GetProductRequest) (*pb.Product, error) {
+	return &pb.Product{Id: req.Id}, nil
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	m := metrics.New(nil)
+
+	gateway := NewGatewayMux()
+	if err := pb.RegisterProductServiceHandlerServer(context.Background(), gateway, testProductServer{}); err != nil {
+		t.Fatal(err)
+	}
+	r := gorilla.NewRouter()
+	r.Use(MetricsMiddleware(m))
+	r.PathPrefix("/v1/").Handler(gateway)
+	r.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {}).Methods("GET")
+
+	for _, path := range []string{
+		"/v1/products/550e8400-e29b-41d4-a716-446655440000",
+		"/v1/products/550e8400-e29b-41d4-a716-446655440001",
+		"/v1/products:search?q=meja",
+		"/healthz",
+	} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// Product ids collapse into the gateway path template, SearchProducts is
+	// unimplemented by the test server (501) and gorilla routes keep their
+	// own template.
+	want := `
+# HELP http_server_requests_total Number of HTTP requests completed, by method, route and status.
+# TYPE http_server_requests_total counter
+http_server_requests_total{method="GET",route="/healthz",status="200"} 1
+http_server_requests_total{method="GET",route="/v1/products/{id}",status="200"} 2
+http_server_requests_total{method="GET",route="/v1/products:search",status="501"} 1
+`
+	if err := testutil.GatherAndCompare(m.Gatherer(), strings.NewReader(want), "http_server_requests_total"); err != nil {
+		t.Error(err)
+	}
+
+	labels := map[string]string{"method": "GET", "route": "/v1/products/{id}", "status": "200"}
+	if got := histogramCount(t, m.Gatherer(), "http_server_request_duration_seconds", labels); got != 2 {
+		t.Errorf("latency samples = %d, want 2", got)
+	}
+}
+
+// histogramCount returns the number of observations of the histogram series
+// with exactly the given labels, or 0 when there is none.
+func histogramCount(t *testing.T, g prometheus.Gatherer, name string, labels map[string]string) uint64 {
+	t.Helper()
+	families, err := g.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	series:
+		for _, metric := range family.GetMetric() {
+			if len(metric.GetLabel()) != len(labels) {
+				continue
+			}
+			for _, label := range metric.GetLabel() {
+				if labels[label.GetName()] != label.GetValue() {
+					continue series
+				}
+			}
+			return metric.GetHistogram().GetSampleCount()
+		}
+	}
+	return 0
+}
+
+func withLabel(labels map[string]string, name, value string) map[string]string {
+	out := map[string]string{name: value}
+	for k, v := range labels {
+		out[k] = v
+	}
+	return out
+}
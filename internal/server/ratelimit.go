@@ -0,0 +1,185 @@
+package server
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-backend-service/internal/auth"
+	"go-backend-service/internal/config"
+	"go-backend-service/internal/logging"
+	"go-backend-service/internal/ratelimit"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// rateLimitHeaders are sent as gRPC metadata and forwarded by the gateway
+// under the same names.
+var rateLimitHeaders = map[string]string{
+	"ratelimit-limit":     "RateLimit-Limit",
+	"ratelimit-remaining": "RateLimit-Remaining",
+	"ratelimit-reset":     "RateLimit-Reset",
+	"retry-after":         "Retry-After",
+}
+
+// unlimitedPaths are never rate limited so that probes and scrapes keep
+// working while a client is throttled.
+var unlimitedPaths = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+	"/metrics": true,
+}
+
+// RateLimiter throttles RPCs per client IP and then per client and method,
+// and HTTP requests per client IP. Clients are identified by the
+// authenticated subject (JWT subject or "apikey:<id>"), or else by IP
+// address.
+type RateLimiter struct {
+	limiter *ratelimit.Limiter
+	rpc     ratelimit.Quota
+	http    ratelimit.Quota
+	ip      ratelimit.Quota
+	methods map[string]ratelimit.Quota
+}
+
+func NewRateLimiter(cfg config.RateLimitConfig) *RateLimiter {
+	l := &RateLimiter{
+		limiter: ratelimit.New(),
+		rpc:     ratelimit.Quota{Rate: cfg.RPS, Burst: cfg.Burst},
+		http:    ratelimit.Quota{Rate: cfg.HTTPRPS, Burst: cfg.HTTPBurst},
+		ip:      ratelimit.Quota{Rate: cfg.IPRPS, Burst: cfg.IPBurst},
+		methods: make(map[string]ratelimit.Quota, len(cfg.Methods)),
+	}
+	for method, quota := range cfg.Methods {
+		l.methods[method] = ratelimit.Quota{Rate: quota.RPS, Burst: quota.Burst}
+	}
+	return l
+}
+
+// Run evicts idle clients every interval until ctx is done.
+func (l *RateLimiter) Run(ctx context.Context, interval time.Duration) {
+	l.limiter.Run(ctx, interval)
+}
+
+func (l *RateLimiter) allowRPC(ctx context.Context, method string) ratelimit.Result {
+	quota, ok := l.methods[method]
+	if !ok {
+		quota = l.rpc
+	}
+	return l.limiter.Allow("rpc "+rpcClient(ctx)+" "+method, quota)
+}
+
+// ipRateLimitUnaryInterceptor rejects RPCs over the per-IP quota with
+// ResourceExhausted. It runs before authentication, so that a flood of
+// invalid API keys is turned away before each one costs a database lookup.
+func ipRateLimitUnaryInterceptor(l *RateLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		result := l.limiter.Allow("rpc ip:"+rpcClientIP(ctx), l.ip)
+		if !result.Allowed {
+			grpc.SetHeader(ctx, rateLimitMetadata(result))
+			return nil, rateLimited(ctx, result)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// ipRateLimitStreamInterceptor is the streaming counterpart of
+// ipRateLimitUnaryInterceptor.
+func ipRateLimitStreamInterceptor(l *RateLimiter) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		result := l.limiter.Allow("rpc ip:"+rpcClientIP(ss.Context()), l.ip)
+		if !result.Allowed {
+			ss.SetHeader(rateLimitMetadata(result))
+			return rateLimited(ss.Context(), result)
+		}
+		return handler(srv, ss)
+	}
+}
+
+// rateLimitUnaryInterceptor rejects RPCs over quota with ResourceExhausted.
+// It runs after authentication so that it can key on the caller.
+func rateLimitUnaryInterceptor(l *RateLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		result := l.allowRPC(ctx, info.FullMethod)
+		if result.Limit > 0 {
+			grpc.SetHeader(ctx, rateLimitMetadata(result))
+		}
+		if !result.Allowed {
+			return nil, rateLimited(ctx, result)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// rateLimitStreamInterceptor is the streaming counterpart of
+// rateLimitUnaryInterceptor. A stream counts as one request.
+func rateLimitStreamInterceptor(l *RateLimiter) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		result := l.allowRPC(ss.Context(), info.FullMethod)
+		if result.Limit > 0 {
+			ss.SetHeader(rateLimitMetadata(result))
+		}
+		if !result.Allowed {
+			return rateLimited(ss.Context(), result)
+		}
+		return handler(srv, ss)
+	}
+}
+
+func rateLimited(ctx context.Context, result ratelimit.Result) error {
+	logging.FromContext(ctx).Debug("rate limited", "retry_after", result.RetryAfter.String())
+	return status.Error(codes.ResourceExhausted, "rate limit exceeded")
+}
+
+// Middleware rejects HTTP requests over the per-IP quota with 429. Requests
+// that pass are limited again per method by the gRPC server, whose
+// RateLimit-* headers the gateway returns.
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if unlimitedPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		result := l.limiter.Allow("http ip:"+hostOnly(r.RemoteAddr), l.http)
+		if result.Allowed {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		for key, values := range rateLimitMetadata(result) {
+			w.Header().Set(rateLimitHeaders[key], values[0])
+		}
+		logging.FromContext(r.Context()).Debug("rate limited", "retry_after", result.RetryAfter.String())
+		http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+	})
+}
+
+func rateLimitMetadata(result ratelimit.Result) metadata.MD {
+	md := metadata.Pairs(
+		"ratelimit-limit", strconv.Itoa(result.Limit),
+		"ratelimit-remaining", strconv.Itoa(result.Remaining),
+		"ratelimit-reset", strconv.Itoa(ceilSeconds(result.Reset)),
+	)
+	if !result.Allowed {
+		md.Set("retry-after", strconv.Itoa(max(1, ceilSeconds(result.RetryAfter))))
+	}
+	return md
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Min(math.Ceil(d.Seconds()), math.MaxInt32))
+}
+
+// rpcClient identifies the caller of an RPC for rate limiting.
+func rpcClient(ctx context.Context) string {
+	if claims, ok := auth.FromContext(ctx); ok {
+		return "sub:" + claims.Subject
+	}
+	return "ip:" + rpcClientIP(ctx)
+}
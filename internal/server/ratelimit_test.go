@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"go-backend-service/internal/auth"
+	"go-backend-service/internal/config"
+	"go-backend-service/internal/domain"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// countingVerifier rejects every API key, counting the lookups a real
+// verifier would make against the api_keys table.
+type countingVerifier struct {
+	calls atomic.Int64
+}
+
+func (v *countingVerifier) VerifyAPIKey(ctx context.Context, key string) (*domain.APIKey, error) {
+	v.calls.Add(1)
+	return nil, domain.ErrInvalidAPIKey
+}
+
+func TestIPRateLimitRunsBeforeAuthentication(t *testing.T) {
+	verifier := &countingVerifier{}
+	authn, err := auth.NewAuthenticator(context.Background(), config.AuthConfig{APIKeys: true}, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	limiter := NewRateLimiter(config.RateLimitConfig{RPS: 1000, Burst: 1000, IPRPS: 0.001, IPBurst: 5})
+
+	// The order NewGRPCServer chains them in.
+	ipLimit := ipRateLimitUnaryInterceptor(limiter)
+	authenticate := authUnaryInterceptor(authn)
+	info := &grpc.UnaryServerInfo{FullMethod: "/pb.ProductService/CreateProduct"}
+	handler := func(ctx context.Context, req any) (any, error) { return nil, nil }
+	call := func(ctx context.Context) error {
+		_, err := ipLimit(ctx, nil, info, func(ctx context.Context, req any) (any, error) {
+			return authenticate(ctx, req, info, handler)
+		})
+		return err
+	}
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 40000}})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(apiKeyMetadataKey, "gbs_live_guess"))
+
+	codeCounts := map[codes.Code]int{}
+	for range 100 {
+		codeCounts[status.Code(call(ctx))]++
+	}
+
+	if got := verifier.calls.Load(); got != 5 {
+		t.Errorf("API key lookups = %d, want 5 (the per-IP burst)", got)
+	}
+	if codeCounts[codes.ResourceExhausted] != 95 {
+		t.Errorf("codes = %v, want 95 ResourceExhausted", codeCounts)
+	}
+
+	// Another client IP still gets through to authentication.
+	other := peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("198.51.100.2"), Port: 40000}})
+	if code := status.Code(call(other)); code == codes.ResourceExhausted {
+		t.Errorf("other IP got %v", code)
+	}
+}
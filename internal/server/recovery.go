@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"runtime/debug"
+	"sync/atomic"
+
+	"go-backend-service/internal/logging"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// panicsTotal counts panics recovered from RPC handlers and HTTP handlers.
+var panicsTotal atomic.Int64
+
+// PanicCount returns the number of panics recovered since the process started.
+func PanicCount() int64 {
+	return panicsTotal.Load()
+}
+
+// recoveryUnaryInterceptor turns a panic in a handler into an Internal error.
+func recoveryUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = recovered(ctx, p)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// recoveryStreamInterceptor is the streaming counterpart of recoveryUnaryInterceptor.
+func recoveryStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = recovered(ss.Context(), p)
+		}
+	}()
+	return handler(srv, ss)
+}
+
+func recovered(ctx context.Context, p any) error {
+	panicsTotal.Add(1)
+	logging.FromContext(ctx).Error("panic recovered", "panic", p, "stack", string(debug.Stack()))
+	return status.Error(codes.Internal, "internal error")
+}
+
+// RecoveryMiddleware turns a panic in an HTTP handler into a 500 response
+// shaped like a gateway error. http.ErrAbortHandler is re-raised so the
+// server can abort the response as intended.
+func RecoveryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+			recovered(r.Context(), p)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"code":13,"message":"internal error","details":[]}`))
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
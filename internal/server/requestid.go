@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"go-backend-service/internal/requestid"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDMiddleware accepts the caller's X-Request-ID or generates one,
+// stores it in the request context and echoes it in the response.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+		w.Header().Set(requestid.Header, id)
+		next.ServeHTTP(w, r.WithContext(requestid.NewContext(r.Context(), id)))
+	})
+}
+
+// gatewayRequestIDMetadata forwards the request id from the HTTP request
+// context to the gRPC server. Use it with runtime.WithMetadata.
+func gatewayRequestIDMetadata(_ context.Context, r *http.Request) metadata.MD {
+	id := requestid.FromContext(r.Context())
+	if id == "" {
+		return nil
+	}
+	return metadata.Pairs(requestid.MetadataKey, id)
+}
+
+// requestIDUnaryInterceptor reads the x-request-id metadata or generates an
+// id, stores it in the context and returns it in the trailer.
+func requestIDUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	id := incomingRequestID(ctx)
+	grpc.SetTrailer(ctx, metadata.Pairs(requestid.MetadataKey, id))
+	return handler(requestid.NewContext(ctx, id), req)
+}
+
+// requestIDStreamInterceptor is the streaming counterpart of requestIDUnaryInterceptor.
+func requestIDStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	id := incomingRequestID(ss.Context())
+	ss.SetTrailer(metadata.Pairs(requestid.MetadataKey, id))
+	ctx := requestid.NewContext(ss.Context(), id)
+	return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+}
+
+func incomingRequestID(ctx context.Context) string {
+	if id := firstMetadata(ctx, requestid.MetadataKey); requestid.Valid(id) {
+		return id
+	}
+	return requestid.New()
+}
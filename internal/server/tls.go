@@ -0,0 +1,30 @@
+package server
+
+import (
+	"crypto/tls"
+
+	"go-backend-service/internal/config"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// ServerCredentials returns the transport credentials for the gRPC server.
+func ServerCredentials(cfg config.TLSConfig) (credentials.TransportCredentials, error) {
+	if !cfg.Enabled {
+		return insecure.NewCredentials(), nil
+	}
+	return credentials.NewServerTLSFromFile(cfg.CertFile, cfg.KeyFile)
+}
+
+// ClientCredentials returns the transport credentials the HTTP gateway uses
+// to dial the gRPC server.
+func ClientCredentials(cfg config.TLSConfig) (credentials.TransportCredentials, error) {
+	if !cfg.Enabled {
+		return insecure.NewCredentials(), nil
+	}
+	if cfg.CAFile != "" {
+		return credentials.NewClientTLSFromFile(cfg.CAFile, "")
+	}
+	return credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12}), nil
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// validator is implemented by messages generated with protoc-gen-validate.
+type validator interface {
+	ValidateAll() error
+}
+
+// fieldError is implemented by the per-message ValidationError types
+// generated by protoc-gen-validate.
+type fieldError interface {
+	Field() string
+	Reason() string
+}
+
+// validationUnaryInterceptor rejects requests that violate the
+// (validate.rules) annotations in the proto definitions.
+func validationUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// validationStreamInterceptor validates every message received on a stream.
+func validationStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &validatingServerStream{ServerStream: ss})
+}
+
+type validatingServerStream struct {
+	grpc.ServerStream
+}
+
+func (s *validatingServerStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return validate(m)
+}
+
+// validate returns an InvalidArgument status with google.rpc.BadRequest
+// field violations when msg fails validation.
+func validate(msg any) error {
+	v, ok := msg.(validator)
+	if !ok {
+		return nil
+	}
+	err := v.ValidateAll()
+	if err == nil {
+		return nil
+	}
+
+	var errs []error
+	if multi, ok := err.(interface{ AllErrors() []error }); ok {
+		errs = multi.AllErrors()
+	} else {
+		errs = []error{err}
+	}
+
+	badRequest := &errdetails.BadRequest{}
+	for _, e := range errs {
+		var fe fieldError
+		if !errors.As(e, &fe) {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Description: e.Error(),
+			})
+			continue
+		}
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       protoFieldName(msg, fe.Field()),
+			Description: fe.Reason(),
+		})
+	}
+
+	st := status.New(codes.InvalidArgument, "invalid request: "+err.Error())
+	if withDetails, detailErr := st.WithDetails(badRequest); detailErr == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// protoFieldName turns the Go field name reported by protoc-gen-validate
+// (e.g. NamaType) into the proto field name (nama_type).
+func protoFieldName(msg any, goName string) string {
+	m, ok := msg.(proto.Message)
+	if !ok {
+		return goName
+	}
+	fields := m.ProtoReflect().Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		name := string(fields.Get(i).Name())
+		if strings.EqualFold(strings.ReplaceAll(name, "_", ""), goName) {
+			return name
+		}
+	}
+	return goName
+}
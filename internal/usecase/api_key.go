@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-backend-service/internal/domain"
+	"go-backend-service/internal/logging"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// apiKeyPrefix marks the keys issued by this service, e.g. in secret scanners.
+	apiKeyPrefix = "gbs_"
+	// apiKeyDisplayLen is how much of a key is kept in clear to recognise it.
+	apiKeyDisplayLen = len(apiKeyPrefix) + 8
+	// lastUsedResolution limits last_used_at writes to one per key per interval.
+	lastUsedResolution = time.Minute
+)
+
+type apiKeyUsecase struct {
+	apiKeyRepo domain.APIKeyRepository
+}
+
+func NewAPIKeyUsecase(apiKeyRepo domain.APIKeyRepository) domain.APIKeyUsecase {
+	return &apiKeyUsecase{
+		apiKeyRepo: apiKeyRepo,
+	}
+}
+
+func (u *apiKeyUsecase) CreateAPIKey(ctx context.Context, apiKey *domain.APIKey) (string, error) {
+	ctx, span := tracer.Start(ctx, "APIKeyUsecase.CreateAPIKey")
+	defer span.End()
+
+	if apiKey.ExpiresAt != nil && !apiKey.ExpiresAt.After(time.Now()) {
+		return "", domain.NewError(domain.ErrInvalidArgument, "INVALID_FIELD", "expires_at must be in the future").
+			WithMetadata("field", "expires_at")
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("generate api key: %w", err)
+	}
+	key := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	apiKey.ID = uuid.New().String()
+	apiKey.Prefix = key[:apiKeyDisplayLen]
+	if err := u.apiKeyRepo.Create(ctx, apiKey, hashAPIKey(key)); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+func (u *apiKeyUsecase) ListAPIKeys(ctx context.Context, includeRevoked bool) ([]*domain.APIKey, error) {
+	ctx, span := tracer.Start(ctx, "APIKeyUsecase.ListAPIKeys")
+	defer span.End()
+
+	return u.apiKeyRepo.List(ctx, includeRevoked)
+}
+
+func (u *apiKeyUsecase) RevokeAPIKey(ctx context.Context, id string) (*domain.APIKey, error) {
+	ctx, span := tracer.Start(ctx, "APIKeyUsecase.RevokeAPIKey")
+	defer span.End()
+
+	return u.apiKeyRepo.Revoke(ctx, id)
+}
+
+func (u *apiKeyUsecase) VerifyAPIKey(ctx context.Context, key string) (*domain.APIKey, error) {
+	ctx, span := tracer.Start(ctx, "APIKeyUsecase.VerifyAPIKey")
+	defer span.End()
+
+	apiKey, err := u.apiKeyRepo.GetByHash(ctx, hashAPIKey(key))
+	if errors.Is(err, domain.ErrAPIKeyNotFound) {
+		return nil, domain.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !apiKey.Active(now) {
+		return nil, domain.ErrInvalidAPIKey
+	}
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= lastUsedResolution {
+		// Tracking is best effort and must not fail the call.
+		if err := u.apiKeyRepo.TouchLastUsed(ctx, apiKey.ID, now); err != nil {
+			logging.FromContext(ctx).Warn("record api key use", "api_key_id", apiKey.ID, "error", err)
+		} else {
+			apiKey.LastUsedAt = &now
+		}
+	}
+	return apiKey, nil
+}
+
+func (u *apiKeyUsecase) EnsureAPIKey(ctx context.Context, apiKey *domain.APIKey, key string) (bool, error) {
+	ctx, span := tracer.Start(ctx, "APIKeyUsecase.EnsureAPIKey")
+	defer span.End()
+
+	keyHash := hashAPIKey(key)
+	_, err := u.apiKeyRepo.GetByHash(ctx, keyHash)
+	if err == nil {
+		return false, nil
+	}
+	if !errors.Is(err, domain.ErrAPIKeyNotFound) {
+		return false, err
+	}
+
+	apiKey.ID = uuid.New().String()
+	apiKey.Prefix = key[:min(len(key), apiKeyDisplayLen)]
+	if err := u.apiKeyRepo.Create(ctx, apiKey, keyHash); err != nil {
+		// Another replica may have stored it first.
+		if _, getErr := u.apiKeyRepo.GetByHash(ctx, keyHash); getErr == nil {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
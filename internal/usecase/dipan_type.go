@@ -3,44 +3,55 @@ package usecase
 import (
 	"context"
 
-	"go-backend-service/internal/repository"
-	"go-backend-service/pkg/pb"
+	"go-backend-service/internal/domain"
 )
 
-type DipanTypeUsecase interface {
-	Create(ctx context.Context, dipanType *pb.DipanType) error
-	GetByID(ctx context.Context, id int32) (*pb.DipanType, error)
-	List(ctx context.Context, page, limit int32) ([]*pb.DipanType, int32, error)
-	Update(ctx context.Context, dipanType *pb.DipanType) error
-	Delete(ctx context.Context, id int32) error
-}
-
 type dipanTypeUsecase struct {
-	dipanTypeRepo repository.DipanTypeRepository
+	dipanTypeRepo domain.DipanTypeRepository
 }
 
-func NewDipanTypeUsecase(dipanTypeRepo repository.DipanTypeRepository) DipanTypeUsecase {
+func NewDipanTypeUsecase(dipanTypeRepo domain.DipanTypeRepository) domain.DipanTypeUsecase {
 	return &dipanTypeUsecase{
 		dipanTypeRepo: dipanTypeRepo,
 	}
 }
 
-func (u *dipanTypeUsecase) Create(ctx context.Context, dipanType *pb.DipanType) error {
+func (u *dipanTypeUsecase) CreateDipanType(ctx context.Context, dipanType *domain.DipanType) error {
+	ctx, span := tracer.Start(ctx, "DipanTypeUsecase.CreateDipanType")
+	defer span.End()
+
 	return u.dipanTypeRepo.Create(ctx, dipanType)
 }
 
-func (u *dipanTypeUsecase) GetByID(ctx context.Context, id int32) (*pb.DipanType, error) {
+func (u *dipanTypeUsecase) GetDipanType(ctx context.Context, id int32) (*domain.DipanType, error) {
+	ctx, span := tracer.Start(ctx, "DipanTypeUsecase.GetDipanType")
+	defer span.End()
+
 	return u.dipanTypeRepo.GetByID(ctx, id)
 }
 
-func (u *dipanTypeUsecase) List(ctx context.Context, page, limit int32) ([]*pb.DipanType, int32, error) {
-	return u.dipanTypeRepo.List(ctx, page, limit)
+func (u *dipanTypeUsecase) ListDipanTypes(ctx context.Context, query domain.ListQuery) ([]*domain.DipanType, domain.PageResult, error) {
+	ctx, span := tracer.Start(ctx, "DipanTypeUsecase.ListDipanTypes")
+	defer span.End()
+
+	page, err := query.Page.Normalize()
+	if err != nil {
+		return nil, domain.PageResult{}, err
+	}
+	query.Page = page
+	return u.dipanTypeRepo.List(ctx, query)
 }
 
-func (u *dipanTypeUsecase) Update(ctx context.Context, dipanType *pb.DipanType) error {
+func (u *dipanTypeUsecase) UpdateDipanType(ctx context.Context, dipanType *domain.DipanType) error {
+	ctx, span := tracer.Start(ctx, "DipanTypeUsecase.UpdateDipanType")
+	defer span.End()
+
 	return u.dipanTypeRepo.Update(ctx, dipanType)
 }
 
-func (u *dipanTypeUsecase) Delete(ctx context.Context, id int32) error {
-	return u.dipanTypeRepo.Delete(ctx, id)
+func (u *dipanTypeUsecase) DeleteDipanType(ctx context.Context, id int32, version int64) error {
+	ctx, span := tracer.Start(ctx, "DipanTypeUsecase.DeleteDipanType")
+	defer span.End()
+
+	return u.dipanTypeRepo.Delete(ctx, id, version)
 }
@@ -2,6 +2,10 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"go-backend-service/internal/domain"
@@ -9,29 +13,235 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	// defaultReservationTTL is how long ReserveStock holds stock when the
+	// caller gives no ttl.
+	defaultReservationTTL = 15 * time.Minute
+	maxReservationTTL     = 24 * time.Hour
+
+	// updateAttempts bounds how often UpdateProduct without an etag re-reads
+	// a product that another write changed between its read and its update.
+	updateAttempts = 3
+)
+
 type productUsecase struct {
 	productRepo domain.ProductRepository
+	stockRepo   domain.StockRepository
 }
 
-func NewProductUsecase(productRepo domain.ProductRepository) domain.ProductUsecase {
+func NewProductUsecase(productRepo domain.ProductRepository, stockRepo domain.StockRepository) domain.ProductUsecase {
 	return &productUsecase{
 		productRepo: productRepo,
+		stockRepo:   stockRepo,
 	}
 }
 
 func (u *productUsecase) CreateProduct(ctx context.Context, product *domain.Product) error {
+	ctx, span := tracer.Start(ctx, "ProductUsecase.CreateProduct")
+	defer span.End()
+
 	now := time.Now()
 	product.ID = uuid.New().String()
 	product.CreatedAt = now
 	product.UpdatedAt = now
+	product.Version = 1
 
 	return u.productRepo.Create(ctx, product)
 }
 
 func (u *productUsecase) GetProduct(ctx context.Context, id string) (*domain.Product, error) {
+	ctx, span := tracer.Start(ctx, "ProductUsecase.GetProduct")
+	defer span.End()
+
 	return u.productRepo.GetByID(ctx, id)
 }
 
-func (u *productUsecase) ListProducts(ctx context.Context, page, limit int32) ([]*domain.Product, int32, error) {
-	return u.productRepo.List(ctx, page, limit)
+func (u *productUsecase) ListProducts(ctx context.Context, query domain.ListQuery) ([]*domain.Product, domain.PageResult, error) {
+	ctx, span := tracer.Start(ctx, "ProductUsecase.ListProducts")
+	defer span.End()
+
+	page, err := query.Page.Normalize()
+	if err != nil {
+		return nil, domain.PageResult{}, err
+	}
+	query.Page = page
+	return u.productRepo.List(ctx, query)
+}
+
+func (u *productUsecase) SearchProducts(ctx context.Context, text string, page domain.PageRequest) ([]*domain.ProductSearchResult, domain.PageResult, error) {
+	ctx, span := tracer.Start(ctx, "ProductUsecase.SearchProducts")
+	defer span.End()
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, domain.PageResult{}, domain.NewError(domain.ErrInvalidArgument, "INVALID_FIELD",
+			"search query must not be empty").WithMetadata("field", "q")
+	}
+	page, err := page.Normalize()
+	if err != nil {
+		return nil, domain.PageResult{}, err
+	}
+	return u.productRepo.Search(ctx, text, page)
+}
+
+func (u *productUsecase) UpdateProduct(ctx context.Context, product *domain.Product, fields []string) error {
+	ctx, span := tracer.Start(ctx, "ProductUsecase.UpdateProduct")
+	defer span.End()
+
+	if len(fields) == 0 {
+		fields = []string{"name", "description", "price"}
+	}
+	for _, field := range fields {
+		switch field {
+		case "name":
+			if product.Name == "" {
+				return domain.NewError(domain.ErrInvalidArgument, "INVALID_FIELD",
+					"product name must not be empty").WithMetadata("field", field)
+			}
+		case "description", "price":
+		case "stock":
+			return domain.NewError(domain.ErrInvalidArgument, "UNSUPPORTED_UPDATE_FIELD",
+				"stock is changed with AdjustStock, which records the change in the stock ledger").WithMetadata("field", field)
+		default:
+			return domain.NewError(domain.ErrInvalidArgument, "UNSUPPORTED_UPDATE_FIELD",
+				fmt.Sprintf("unsupported update field %q", field)).WithMetadata("field", field)
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		existing, err := u.productRepo.GetByID(ctx, product.ID)
+		if err != nil {
+			return err
+		}
+		// The repository also checks existing.Version, in case the product
+		// changes between the read and the write.
+		if product.Version != 0 && product.Version != existing.Version {
+			return domain.ErrVersionMismatch.WithMetadata("current_version", strconv.FormatInt(existing.Version, 10))
+		}
+
+		for _, field := range fields {
+			switch field {
+			case "name":
+				existing.Name = product.Name
+			case "description":
+				existing.Description = product.Description
+			case "price":
+				existing.Price = product.Price
+			}
+		}
+		existing.UpdatedAt = time.Now()
+
+		err = u.productRepo.Update(ctx, existing)
+		// Without an etag the caller set no precondition, so a concurrent
+		// write is merged into by starting over rather than reported.
+		if product.Version == 0 && errors.Is(err, domain.ErrVersionMismatch) && attempt < updateAttempts {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		*product = *existing
+		return nil
+	}
+}
+
+func (u *productUsecase) DeleteProduct(ctx context.Context, id string, version int64) error {
+	ctx, span := tracer.Start(ctx, "ProductUsecase.DeleteProduct")
+	defer span.End()
+
+	return u.productRepo.Delete(ctx, id, version)
+}
+
+func (u *productUsecase) AdjustStock(ctx context.Context, movement *domain.StockMovement) (*domain.Product, error) {
+	ctx, span := tracer.Start(ctx, "ProductUsecase.AdjustStock")
+	defer span.End()
+
+	switch movement.Type {
+	case domain.StockReceive, domain.StockReturn, domain.StockSell:
+		if movement.Quantity <= 0 {
+			return nil, domain.NewError(domain.ErrInvalidArgument, "INVALID_FIELD",
+				fmt.Sprintf("%s quantity must be positive", movement.Type)).WithMetadata("field", "quantity")
+		}
+		if movement.Type == domain.StockSell {
+			movement.Quantity = -movement.Quantity
+		}
+	case domain.StockCorrection:
+		if movement.Quantity == 0 {
+			return nil, domain.NewError(domain.ErrInvalidArgument, "INVALID_FIELD",
+				"correction quantity must not be zero").WithMetadata("field", "quantity")
+		}
+		if strings.TrimSpace(movement.Reason) == "" {
+			return nil, domain.NewError(domain.ErrInvalidArgument, "INVALID_FIELD",
+				"correction requires a reason").WithMetadata("field", "reason")
+		}
+	default:
+		return nil, domain.NewError(domain.ErrInvalidArgument, "INVALID_FIELD",
+			fmt.Sprintf("unsupported stock movement type %q", movement.Type)).WithMetadata("field", "type")
+	}
+
+	movement.ID = uuid.New().String()
+	movement.CreatedAt = time.Now()
+
+	return u.stockRepo.Apply(ctx, movement)
+}
+
+func (u *productUsecase) ListStockMovements(ctx context.Context, productID string, query domain.ListQuery) ([]*domain.StockMovement, domain.PageResult, error) {
+	ctx, span := tracer.Start(ctx, "ProductUsecase.ListStockMovements")
+	defer span.End()
+
+	page, err := query.Page.Normalize()
+	if err != nil {
+		return nil, domain.PageResult{}, err
+	}
+	query.Page = page
+	return u.stockRepo.ListMovements(ctx, productID, query)
+}
+
+func (u *productUsecase) ReserveStock(ctx context.Context, reservation *domain.StockReservation, ttl time.Duration) (*domain.Product, error) {
+	ctx, span := tracer.Start(ctx, "ProductUsecase.ReserveStock")
+	defer span.End()
+
+	if reservation.Quantity <= 0 {
+		return nil, domain.NewError(domain.ErrInvalidArgument, "INVALID_FIELD",
+			"reservation quantity must be positive").WithMetadata("field", "quantity")
+	}
+	if ttl <= 0 {
+		ttl = defaultReservationTTL
+	}
+	if ttl > maxReservationTTL {
+		return nil, domain.NewError(domain.ErrInvalidArgument, "INVALID_FIELD",
+			fmt.Sprintf("ttl must not exceed %s", maxReservationTTL)).WithMetadata("field", "ttl_seconds")
+	}
+
+	now := time.Now()
+	reservation.ID = uuid.New().String()
+	reservation.Status = domain.ReservationActive
+	reservation.ExpiresAt = now.Add(ttl)
+	reservation.CreatedAt = now
+	reservation.UpdatedAt = now
+
+	return u.stockRepo.Reserve(ctx, reservation)
+}
+
+func (u *productUsecase) CommitReservation(ctx context.Context, id, actor string) (*domain.StockReservation, *domain.Product, error) {
+	ctx, span := tracer.Start(ctx, "ProductUsecase.CommitReservation")
+	defer span.End()
+
+	movement := &domain.StockMovement{
+		ID:        uuid.New().String(),
+		Type:      domain.StockSell,
+		Reason:    "committed reservation " + id,
+		Actor:     actor,
+		Reference: id,
+		CreatedAt: time.Now(),
+	}
+	return u.stockRepo.Commit(ctx, id, movement)
+}
+
+func (u *productUsecase) ReleaseReservation(ctx context.Context, id string) (*domain.StockReservation, *domain.Product, error) {
+	ctx, span := tracer.Start(ctx, "ProductUsecase.ReleaseReservation")
+	defer span.End()
+
+	return u.stockRepo.Release(ctx, id, time.Now())
 }
@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"go-backend-service/internal/domain"
+	"go-backend-service/internal/logging"
+)
+
+// ReservationSweeper releases expired stock reservations in the background.
+// ReserveStock already ignores the lapsed reservations of the product it
+// reserves; the sweeper frees the stock of the others.
+type ReservationSweeper struct {
+	stockRepo domain.StockRepository
+}
+
+func NewReservationSweeper(stockRepo domain.StockRepository) *ReservationSweeper {
+	return &ReservationSweeper{
+		stockRepo: stockRepo,
+	}
+}
+
+// Run sweeps every interval until ctx is done.
+func (s *ReservationSweeper) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Sweep(ctx)
+		}
+	}
+}
+
+// Sweep expires the reservations past their expiry and logs how many.
+func (s *ReservationSweeper) Sweep(ctx context.Context) {
+	ctx, span := tracer.Start(ctx, "ReservationSweeper.Sweep")
+	defer span.End()
+
+	logger := logging.FromContext(ctx)
+	released, err := s.stockRepo.ReleaseExpired(ctx, time.Now())
+	if err != nil && ctx.Err() == nil {
+		logger.Error("release expired reservations", "error", err, "released", released)
+		return
+	}
+	if released > 0 {
+		logger.Info("released expired reservations", "released", released)
+	}
+}
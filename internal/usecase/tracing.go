@@ -0,0 +1,5 @@
+package usecase
+
+import "go-backend-service/internal/tracing"
+
+var tracer = tracing.Tracer("go-backend-service/internal/usecase")
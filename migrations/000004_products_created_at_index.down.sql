@@ -0,0 +1 @@
+DROP INDEX IF EXISTS products_created_at_id_idx;
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- Keyset pagination of ListProducts orders by (created_at, id).
+CREATE INDEX IF NOT EXISTS products_created_at_id_idx ON products (created_at DESC, id DESC);
@@ -0,0 +1,10 @@
+-- SearchProducts matches words of the name (weight A) and description
+-- (weight B). The 'simple' configuration does not stem, since product names
+-- are mostly Indonesian.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(description, '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS products_search_vector_idx ON products USING GIN (search_vector);
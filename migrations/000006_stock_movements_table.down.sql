@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS stock_movements;
+DROP FUNCTION IF EXISTS stock_movements_append_only();
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_stock_check;
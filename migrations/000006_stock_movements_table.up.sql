@@ -0,0 +1,51 @@
+-- Ledger of stock changes. Rows are never updated or deleted, and are kept
+-- when their product is deleted.
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id VARCHAR(36) PRIMARY KEY,
+    product_id VARCHAR(36) NOT NULL,
+    type VARCHAR(16) NOT NULL CHECK (type IN ('receive', 'sell', 'return', 'correction')),
+    quantity INT NOT NULL,
+    stock_after INT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    actor VARCHAR(255) NOT NULL,
+    reference VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS stock_movements_product_created_at_idx
+    ON stock_movements (product_id, created_at DESC, id DESC);
+
+CREATE OR REPLACE FUNCTION stock_movements_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'stock_movements is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS stock_movements_append_only ON stock_movements;
+CREATE TRIGGER stock_movements_append_only
+    BEFORE UPDATE OR DELETE ON stock_movements
+    FOR EACH ROW EXECUTE FUNCTION stock_movements_append_only();
+
+-- Stock is only changed through AdjustStock, which never takes it below zero.
+-- Earlier schemas allowed negative stock, so clamp it before adding the
+-- check, recording each clamp as a correction so that the ledger still
+-- accounts for every change of the stock.
+INSERT INTO stock_movements (id, product_id, type, quantity, stock_after, reason, actor)
+SELECT gen_random_uuid()::text, id, 'correction', -stock, 0,
+    'negative stock clamped to 0 by migration 000006', 'migration'
+FROM products
+WHERE stock < 0;
+
+UPDATE products SET stock = 0, updated_at = CURRENT_TIMESTAMP WHERE stock < 0;
+
+-- scripts/init_db.sql may already have created the constraint.
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'products_stock_check' AND conrelid = 'products'::regclass
+    ) THEN
+        ALTER TABLE products ADD CONSTRAINT products_stock_check CHECK (stock >= 0);
+    END IF;
+END;
+$$;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS stock_reservations;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_reserved_check;
+ALTER TABLE products DROP COLUMN IF EXISTS reserved;
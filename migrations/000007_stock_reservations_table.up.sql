@@ -0,0 +1,33 @@
+-- reserved is the part of stock held by active reservations, kept in step
+-- with stock_reservations under the product row lock.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS reserved INT NOT NULL DEFAULT 0;
+
+-- scripts/init_db.sql may already have created the constraint.
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'products_reserved_check' AND conrelid = 'products'::regclass
+    ) THEN
+        ALTER TABLE products ADD CONSTRAINT products_reserved_check CHECK (reserved >= 0 AND reserved <= stock);
+    END IF;
+END;
+$$;
+
+CREATE TABLE IF NOT EXISTS stock_reservations (
+    id VARCHAR(36) PRIMARY KEY,
+    product_id VARCHAR(36) NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    status VARCHAR(16) NOT NULL CHECK (status IN ('active', 'committed', 'released', 'expired')),
+    reference VARCHAR(255) NOT NULL DEFAULT '',
+    actor VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The sweeper and ReserveStock look up active reservations past expiry.
+CREATE INDEX IF NOT EXISTS stock_reservations_active_expires_at_idx
+    ON stock_reservations (expires_at) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS stock_reservations_active_product_idx
+    ON stock_reservations (product_id, expires_at) WHERE status = 'active';
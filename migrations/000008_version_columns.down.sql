@@ -0,0 +1,2 @@
+ALTER TABLE dipan_types DROP COLUMN IF EXISTS version;
+ALTER TABLE products DROP COLUMN IF EXISTS version;
@@ -0,0 +1,4 @@
+-- Optimistic concurrency: every update of the entity increments version,
+-- which the API exposes as the etag. Stock and reservation writes do not.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE dipan_types ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
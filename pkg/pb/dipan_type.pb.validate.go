@@ -0,0 +1,1033 @@
+// Code generated by protoc-gen-validate. DO NOT EDIT.
+// source: pkg/pb/dipan_type.proto
+
+package pb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// ensure the imports are used
+var (
+	_ = bytes.MinRead
+	_ = errors.New("")
+	_ = fmt.Print
+	_ = utf8.UTFMax
+	_ = (*regexp.Regexp)(nil)
+	_ = (*strings.Reader)(nil)
+	_ = net.IPv4len
+	_ = time.Duration(0)
+	_ = (*url.URL)(nil)
+	_ = (*mail.Address)(nil)
+	_ = anypb.Any{}
+	_ = sort.Sort
+)
+
+// Validate checks the field values on DipanType with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *DipanType) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on DipanType with the rules defined in
+// the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in DipanTypeMultiError, or nil
+// if none found.
+func (m *DipanType) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *DipanType) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Id
+
+	// no validation rules for NamaType
+
+	// no validation rules for CreatedAt
+
+	// no validation rules for UpdatedAt
+
+	// no validation rules for Etag
+
+	if len(errors) > 0 {
+		return DipanTypeMultiError(errors)
+	}
+
+	return nil
+}
+
+// DipanTypeMultiError is an error wrapping multiple validation errors returned
+// by DipanType.ValidateAll() if the designated constraints aren't met.
+type DipanTypeMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m DipanTypeMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m DipanTypeMultiError) AllErrors() []error { return m }
+
+// DipanTypeValidationError is the validation error returned by
+// DipanType.Validate if the designated constraints aren't met.
+type DipanTypeValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e DipanTypeValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e DipanTypeValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e DipanTypeValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e DipanTypeValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e DipanTypeValidationError) ErrorName() string { return "DipanTypeValidationError" }
+
+// Error satisfies the builtin error interface
+func (e DipanTypeValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sDipanType.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = DipanTypeValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = DipanTypeValidationError{}
+
+// Validate checks the field values on CreateDipanTypeRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *CreateDipanTypeRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on CreateDipanTypeRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// CreateDipanTypeRequestMultiError, or nil if none found.
+func (m *CreateDipanTypeRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *CreateDipanTypeRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if l := utf8.RuneCountInString(m.GetNamaType()); l < 1 || l > 255 {
+		err := CreateDipanTypeRequestValidationError{
+			field:  "NamaType",
+			reason: "value length must be between 1 and 255 runes, inclusive",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return CreateDipanTypeRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// CreateDipanTypeRequestMultiError is an error wrapping multiple validation
+// errors returned by CreateDipanTypeRequest.ValidateAll() if the designated
+// constraints aren't met.
+type CreateDipanTypeRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m CreateDipanTypeRequestMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m CreateDipanTypeRequestMultiError) AllErrors() []error { return m }
+
+// CreateDipanTypeRequestValidationError is the validation error returned by
+// CreateDipanTypeRequest.Validate if the designated constraints aren't met.
+type CreateDipanTypeRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e CreateDipanTypeRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e CreateDipanTypeRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e CreateDipanTypeRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e CreateDipanTypeRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e CreateDipanTypeRequestValidationError) ErrorName() string {
+	return "CreateDipanTypeRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e CreateDipanTypeRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sCreateDipanTypeRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = CreateDipanTypeRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = CreateDipanTypeRequestValidationError{}
+
+// Validate checks the field values on GetDipanTypeRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *GetDipanTypeRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on GetDipanTypeRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// GetDipanTypeRequestMultiError, or nil if none found.
+func (m *GetDipanTypeRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *GetDipanTypeRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if m.GetId() <= 0 {
+		err := GetDipanTypeRequestValidationError{
+			field:  "Id",
+			reason: "value must be greater than 0",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return GetDipanTypeRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// GetDipanTypeRequestMultiError is an error wrapping multiple validation
+// errors returned by GetDipanTypeRequest.ValidateAll() if the designated
+// constraints aren't met.
+type GetDipanTypeRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m GetDipanTypeRequestMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m GetDipanTypeRequestMultiError) AllErrors() []error { return m }
+
+// GetDipanTypeRequestValidationError is the validation error returned by
+// GetDipanTypeRequest.Validate if the designated constraints aren't met.
+type GetDipanTypeRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e GetDipanTypeRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e GetDipanTypeRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e GetDipanTypeRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e GetDipanTypeRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e GetDipanTypeRequestValidationError) ErrorName() string {
+	return "GetDipanTypeRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e GetDipanTypeRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sGetDipanTypeRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = GetDipanTypeRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = GetDipanTypeRequestValidationError{}
+
+// Validate checks the field values on ListDipanTypesRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *ListDipanTypesRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ListDipanTypesRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// ListDipanTypesRequestMultiError, or nil if none found.
+func (m *ListDipanTypesRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ListDipanTypesRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if m.GetPage() < 0 {
+		err := ListDipanTypesRequestValidationError{
+			field:  "Page",
+			reason: "value must be greater than or equal to 0",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if m.GetLimit() < 0 {
+		err := ListDipanTypesRequestValidationError{
+			field:  "Limit",
+			reason: "value must be greater than or equal to 0",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if utf8.RuneCountInString(m.GetPageToken()) > 512 {
+		err := ListDipanTypesRequestValidationError{
+			field:  "PageToken",
+			reason: "value length must be at most 512 runes",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	// no validation rules for IncludeTotal
+
+	if utf8.RuneCountInString(m.GetFilter()) > 1024 {
+		err := ListDipanTypesRequestValidationError{
+			field:  "Filter",
+			reason: "value length must be at most 1024 runes",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if utf8.RuneCountInString(m.GetOrderBy()) > 256 {
+		err := ListDipanTypesRequestValidationError{
+			field:  "OrderBy",
+			reason: "value length must be at most 256 runes",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return ListDipanTypesRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// ListDipanTypesRequestMultiError is an error wrapping multiple validation
+// errors returned by ListDipanTypesRequest.ValidateAll() if the designated
+// constraints aren't met.
+type ListDipanTypesRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ListDipanTypesRequestMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ListDipanTypesRequestMultiError) AllErrors() []error { return m }
+
+// ListDipanTypesRequestValidationError is the validation error returned by
+// ListDipanTypesRequest.Validate if the designated constraints aren't met.
+type ListDipanTypesRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ListDipanTypesRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ListDipanTypesRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ListDipanTypesRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ListDipanTypesRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ListDipanTypesRequestValidationError) ErrorName() string {
+	return "ListDipanTypesRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e ListDipanTypesRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sListDipanTypesRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ListDipanTypesRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ListDipanTypesRequestValidationError{}
+
+// Validate checks the field values on ListDipanTypesResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *ListDipanTypesResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ListDipanTypesResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// ListDipanTypesResponseMultiError, or nil if none found.
+func (m *ListDipanTypesResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ListDipanTypesResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	for idx, item := range m.GetDipanTypes() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, ListDipanTypesResponseValidationError{
+						field:  fmt.Sprintf("DipanTypes[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, ListDipanTypesResponseValidationError{
+						field:  fmt.Sprintf("DipanTypes[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return ListDipanTypesResponseValidationError{
+					field:  fmt.Sprintf("DipanTypes[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	// no validation rules for Total
+
+	// no validation rules for NextPageToken
+
+	if len(errors) > 0 {
+		return ListDipanTypesResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// ListDipanTypesResponseMultiError is an error wrapping multiple validation
+// errors returned by ListDipanTypesResponse.ValidateAll() if the designated
+// constraints aren't met.
+type ListDipanTypesResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ListDipanTypesResponseMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ListDipanTypesResponseMultiError) AllErrors() []error { return m }
+
+// ListDipanTypesResponseValidationError is the validation error returned by
+// ListDipanTypesResponse.Validate if the designated constraints aren't met.
+type ListDipanTypesResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ListDipanTypesResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ListDipanTypesResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ListDipanTypesResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ListDipanTypesResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ListDipanTypesResponseValidationError) ErrorName() string {
+	return "ListDipanTypesResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e ListDipanTypesResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sListDipanTypesResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ListDipanTypesResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ListDipanTypesResponseValidationError{}
+
+// Validate checks the field values on UpdateDipanTypeRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *UpdateDipanTypeRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on UpdateDipanTypeRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// UpdateDipanTypeRequestMultiError, or nil if none found.
+func (m *UpdateDipanTypeRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *UpdateDipanTypeRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if m.GetId() <= 0 {
+		err := UpdateDipanTypeRequestValidationError{
+			field:  "Id",
+			reason: "value must be greater than 0",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if l := utf8.RuneCountInString(m.GetNamaType()); l < 1 || l > 255 {
+		err := UpdateDipanTypeRequestValidationError{
+			field:  "NamaType",
+			reason: "value length must be between 1 and 255 runes, inclusive",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if utf8.RuneCountInString(m.GetEtag()) > 64 {
+		err := UpdateDipanTypeRequestValidationError{
+			field:  "Etag",
+			reason: "value length must be at most 64 runes",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return UpdateDipanTypeRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// UpdateDipanTypeRequestMultiError is an error wrapping multiple validation
+// errors returned by UpdateDipanTypeRequest.ValidateAll() if the designated
+// constraints aren't met.
+type UpdateDipanTypeRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m UpdateDipanTypeRequestMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m UpdateDipanTypeRequestMultiError) AllErrors() []error { return m }
+
+// UpdateDipanTypeRequestValidationError is the validation error returned by
+// UpdateDipanTypeRequest.Validate if the designated constraints aren't met.
+type UpdateDipanTypeRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e UpdateDipanTypeRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e UpdateDipanTypeRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e UpdateDipanTypeRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e UpdateDipanTypeRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e UpdateDipanTypeRequestValidationError) ErrorName() string {
+	return "UpdateDipanTypeRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e UpdateDipanTypeRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sUpdateDipanTypeRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = UpdateDipanTypeRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = UpdateDipanTypeRequestValidationError{}
+
+// Validate checks the field values on DeleteDipanTypeRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *DeleteDipanTypeRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on DeleteDipanTypeRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// DeleteDipanTypeRequestMultiError, or nil if none found.
+func (m *DeleteDipanTypeRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *DeleteDipanTypeRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if m.GetId() <= 0 {
+		err := DeleteDipanTypeRequestValidationError{
+			field:  "Id",
+			reason: "value must be greater than 0",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if utf8.RuneCountInString(m.GetEtag()) > 64 {
+		err := DeleteDipanTypeRequestValidationError{
+			field:  "Etag",
+			reason: "value length must be at most 64 runes",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return DeleteDipanTypeRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// DeleteDipanTypeRequestMultiError is an error wrapping multiple validation
+// errors returned by DeleteDipanTypeRequest.ValidateAll() if the designated
+// constraints aren't met.
+type DeleteDipanTypeRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m DeleteDipanTypeRequestMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m DeleteDipanTypeRequestMultiError) AllErrors() []error { return m }
+
+// DeleteDipanTypeRequestValidationError is the validation error returned by
+// DeleteDipanTypeRequest.Validate if the designated constraints aren't met.
+type DeleteDipanTypeRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e DeleteDipanTypeRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e DeleteDipanTypeRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e DeleteDipanTypeRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e DeleteDipanTypeRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e DeleteDipanTypeRequestValidationError) ErrorName() string {
+	return "DeleteDipanTypeRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e DeleteDipanTypeRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sDeleteDipanTypeRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = DeleteDipanTypeRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = DeleteDipanTypeRequestValidationError{}
+
+// Validate checks the field values on DeleteDipanTypeResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *DeleteDipanTypeResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on DeleteDipanTypeResponse with the
+// rules defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// DeleteDipanTypeResponseMultiError, or nil if none found.
+func (m *DeleteDipanTypeResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *DeleteDipanTypeResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Success
+
+	if len(errors) > 0 {
+		return DeleteDipanTypeResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// DeleteDipanTypeResponseMultiError is an error wrapping multiple validation
+// errors returned by DeleteDipanTypeResponse.ValidateAll() if the designated
+// constraints aren't met.
+type DeleteDipanTypeResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m DeleteDipanTypeResponseMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m DeleteDipanTypeResponseMultiError) AllErrors() []error { return m }
+
+// DeleteDipanTypeResponseValidationError is the validation error returned by
+// DeleteDipanTypeResponse.Validate if the designated constraints aren't met.
+type DeleteDipanTypeResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e DeleteDipanTypeResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e DeleteDipanTypeResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e DeleteDipanTypeResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e DeleteDipanTypeResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e DeleteDipanTypeResponseValidationError) ErrorName() string {
+	return "DeleteDipanTypeResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e DeleteDipanTypeResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sDeleteDipanTypeResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = DeleteDipanTypeResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = DeleteDipanTypeResponseValidationError{}
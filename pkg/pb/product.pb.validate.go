@@ -0,0 +1,3294 @@
+// Code generated by protoc-gen-validate. DO NOT EDIT.
+// source: pkg/pb/product.proto
+
+package pb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// ensure the imports are used
+var (
+	_ = bytes.MinRead
+	_ = errors.New("")
+	_ = fmt.Print
+	_ = utf8.UTFMax
+	_ = (*regexp.Regexp)(nil)
+	_ = (*strings.Reader)(nil)
+	_ = net.IPv4len
+	_ = time.Duration(0)
+	_ = (*url.URL)(nil)
+	_ = (*mail.Address)(nil)
+	_ = anypb.Any{}
+	_ = sort.Sort
+)
+
+// define the regex for a UUID once up-front
+var _product_uuidPattern = regexp.MustCompile("^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$")
+
+// Validate checks the field values on Product with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Product) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Product with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in ProductMultiError, or nil if none found.
+func (m *Product) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Product) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Id
+
+	// no validation rules for Name
+
+	// no validation rules for Description
+
+	// no validation rules for Price
+
+	// no validation rules for Stock
+
+	// no validation rules for CreatedAt
+
+	// no validation rules for UpdatedAt
+
+	// no validation rules for ReservedStock
+
+	// no validation rules for AvailableStock
+
+	// no validation rules for Etag
+
+	if len(errors) > 0 {
+		return ProductMultiError(errors)
+	}
+
+	return nil
+}
+
+// ProductMultiError is an error wrapping multiple validation errors returned
+// by Product.ValidateAll() if the designated constraints aren't met.
+type ProductMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ProductMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ProductMultiError) AllErrors() []error { return m }
+
+// ProductValidationError is the validation error returned by Product.Validate
+// if the designated constraints aren't met.
+type ProductValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ProductValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ProductValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ProductValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ProductValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ProductValidationError) ErrorName() string { return "ProductValidationError" }
+
+// Error satisfies the builtin error interface
+func (e ProductValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sProduct.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ProductValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ProductValidationError{}
+
+// Validate checks the field values on CreateProductRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *CreateProductRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on CreateProductRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// CreateProductRequestMultiError, or nil if none found.
+func (m *CreateProductRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *CreateProductRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if l := utf8.RuneCountInString(m.GetName()); l < 1 || l > 255 {
+		err := CreateProductRequestValidationError{
+			field:  "Name",
+			reason: "value length must be between 1 and 255 runes, inclusive",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	// no validation rules for Description
+
+	if val := m.GetPrice(); val < 0 || val >= 1e+08 {
+		err := CreateProductRequestValidationError{
+			field:  "Price",
+			reason: "value must be inside range [0, 1e+08)",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if m.GetStock() < 0 {
+		err := CreateProductRequestValidationError{
+			field:  "Stock",
+			reason: "value must be greater than or equal to 0",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return CreateProductRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// CreateProductRequestMultiError is an error wrapping multiple validation
+// errors returned by CreateProductRequest.ValidateAll() if the designated
+// constraints aren't met.
+type CreateProductRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m CreateProductRequestMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m CreateProductRequestMultiError) AllErrors() []error { return m }
+
+// CreateProductRequestValidationError is the validation error returned by
+// CreateProductRequest.Validate if the designated constraints aren't met.
+type CreateProductRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e CreateProductRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e CreateProductRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e CreateProductRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e CreateProductRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e CreateProductRequestValidationError) ErrorName() string {
+	return "CreateProductRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e CreateProductRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sCreateProductRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = CreateProductRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = CreateProductRequestValidationError{}
+
+// Validate checks the field values on GetProductRequest with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// first error encountered is returned, or nil if there are no violations.
+func (m *GetProductRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on GetProductRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// GetProductRequestMultiError, or nil if none found.
+func (m *GetProductRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *GetProductRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if err := m._validateUuid(m.GetId()); err != nil {
+		err = GetProductRequestValidationError{
+			field:  "Id",
+			reason: "value must be a valid UUID",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return GetProductRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+func (m *GetProductRequest) _validateUuid(uuid string) error {
+	if matched := _product_uuidPattern.MatchString(uuid); !matched {
+		return errors.New("invalid uuid format")
+	}
+
+	return nil
+}
+
+// GetProductRequestMultiError is an error wrapping multiple validation errors
+// returned by GetProductRequest.ValidateAll() if the designated constraints
+// aren't met.
+type GetProductRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m GetProductRequestMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m GetProductRequestMultiError) AllErrors() []error { return m }
+
+// GetProductRequestValidationError is the validation error returned by
+// GetProductRequest.Validate if the designated constraints aren't met.
+type GetProductRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e GetProductRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e GetProductRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e GetProductRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e GetProductRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e GetProductRequestValidationError) ErrorName() string {
+	return "GetProductRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e GetProductRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sGetProductRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = GetProductRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = GetProductRequestValidationError{}
+
+// Validate checks the field values on ListProductsRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *ListProductsRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ListProductsRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// ListProductsRequestMultiError, or nil if none found.
+func (m *ListProductsRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ListProductsRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if m.GetPage() < 0 {
+		err := ListProductsRequestValidationError{
+			field:  "Page",
+			reason: "value must be greater than or equal to 0",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if m.GetLimit() < 0 {
+		err := ListProductsRequestValidationError{
+			field:  "Limit",
+			reason: "value must be greater than or equal to 0",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if utf8.RuneCountInString(m.GetPageToken()) > 512 {
+		err := ListProductsRequestValidationError{
+			field:  "PageToken",
+			reason: "value length must be at most 512 runes",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	// no validation rules for IncludeTotal
+
+	if utf8.RuneCountInString(m.GetFilter()) > 1024 {
+		err := ListProductsRequestValidationError{
+			field:  "Filter",
+			reason: "value length must be at most 1024 runes",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if utf8.RuneCountInString(m.GetOrderBy()) > 256 {
+		err := ListProductsRequestValidationError{
+			field:  "OrderBy",
+			reason: "value length must be at most 256 runes",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return ListProductsRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// ListProductsRequestMultiError is an error wrapping multiple validation
+// errors returned by ListProductsRequest.ValidateAll() if the designated
+// constraints aren't met.
+type ListProductsRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ListProductsRequestMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ListProductsRequestMultiError) AllErrors() []error { return m }
+
+// ListProductsRequestValidationError is the validation error returned by
+// ListProductsRequest.Validate if the designated constraints aren't met.
+type ListProductsRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ListProductsRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ListProductsRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ListProductsRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ListProductsRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ListProductsRequestValidationError) ErrorName() string {
+	return "ListProductsRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e ListProductsRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sListProductsRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ListProductsRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ListProductsRequestValidationError{}
+
+// Validate checks the field values on ListProductsResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *ListProductsResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ListProductsResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// ListProductsResponseMultiError, or nil if none found.
+func (m *ListProductsResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ListProductsResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	for idx, item := range m.GetProducts() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, ListProductsResponseValidationError{
+						field:  fmt.Sprintf("Products[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, ListProductsResponseValidationError{
+						field:  fmt.Sprintf("Products[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return ListProductsResponseValidationError{
+					field:  fmt.Sprintf("Products[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	// no validation rules for Total
+
+	// no validation rules for NextPageToken
+
+	if len(errors) > 0 {
+		return ListProductsResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// ListProductsResponseMultiError is an error wrapping multiple validation
+// errors returned by ListProductsResponse.ValidateAll() if the designated
+// constraints aren't met.
+type ListProductsResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ListProductsResponseMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ListProductsResponseMultiError) AllErrors() []error { return m }
+
+// ListProductsResponseValidationError is the validation error returned by
+// ListProductsResponse.Validate if the designated constraints aren't met.
+type ListProductsResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ListProductsResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ListProductsResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ListProductsResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ListProductsResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ListProductsResponseValidationError) ErrorName() string {
+	return "ListProductsResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e ListProductsResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sListProductsResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ListProductsResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ListProductsResponseValidationError{}
+
+// Validate checks the field values on SearchProductsRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *SearchProductsRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on SearchProductsRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// SearchProductsRequestMultiError, or nil if none found.
+func (m *SearchProductsRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *SearchProductsRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if l := utf8.RuneCountInString(m.GetQ()); l < 1 || l > 256 {
+		err := SearchProductsRequestValidationError{
+			field:  "Q",
+			reason: "value length must be between 1 and 256 runes, inclusive",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if m.GetLimit() < 0 {
+		err := SearchProductsRequestValidationError{
+			field:  "Limit",
+			reason: "value must be greater than or equal to 0",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if utf8.RuneCountInString(m.GetPageToken()) > 512 {
+		err := SearchProductsRequestValidationError{
+			field:  "PageToken",
+			reason: "value length must be at most 512 runes",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	// no validation rules for IncludeTotal
+
+	if len(errors) > 0 {
+		return SearchProductsRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// SearchProductsRequestMultiError is an error wrapping multiple validation
+// errors returned by SearchProductsRequest.ValidateAll() if the designated
+// constraints aren't met.
+type SearchProductsRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m SearchProductsRequestMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m SearchProductsRequestMultiError) AllErrors() []error { return m }
+
+// SearchProductsRequestValidationError is the validation error returned by
+// SearchProductsRequest.Validate if the designated constraints aren't met.
+type SearchProductsRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e SearchProductsRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e SearchProductsRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e SearchProductsRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e SearchProductsRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e SearchProductsRequestValidationError) ErrorName() string {
+	return "SearchProductsRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e SearchProductsRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sSearchProductsRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = SearchProductsRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = SearchProductsRequestValidationError{}
+
+// Validate checks the field values on ProductSearchResult with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *ProductSearchResult) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ProductSearchResult with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// ProductSearchResultMultiError, or nil if none found.
+func (m *ProductSearchResult) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ProductSearchResult) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if all {
+		switch v := interface{}(m.GetProduct()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, ProductSearchResultValidationError{
+					field:  "Product",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, ProductSearchResultValidationError{
+					field:  "Product",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetProduct()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ProductSearchResultValidationError{
+				field:  "Product",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for Rank
+
+	// no validation rules for Snippet
+
+	if len(errors) > 0 {
+		return ProductSearchResultMultiError(errors)
+	}
+
+	return nil
+}
+
+// ProductSearchResultMultiError is an error wrapping multiple validation
+// errors returned by ProductSearchResult.ValidateAll() if the designated
+// constraints aren't met.
+type ProductSearchResultMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ProductSearchResultMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ProductSearchResultMultiError) AllErrors() []error { return m }
+
+// ProductSearchResultValidationError is the validation error returned by
+// ProductSearchResult.Validate if the designated constraints aren't met.
+type ProductSearchResultValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ProductSearchResultValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ProductSearchResultValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ProductSearchResultValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ProductSearchResultValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ProductSearchResultValidationError) ErrorName() string {
+	return "ProductSearchResultValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e ProductSearchResultValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sProductSearchResult.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ProductSearchResultValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ProductSearchResultValidationError{}
+
+// Validate checks the field values on SearchProductsResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *SearchProductsResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on SearchProductsResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// SearchProductsResponseMultiError, or nil if none found.
+func (m *SearchProductsResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *SearchProductsResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	for idx, item := range m.GetResults() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, SearchProductsResponseValidationError{
+						field:  fmt.Sprintf("Results[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, SearchProductsResponseValidationError{
+						field:  fmt.Sprintf("Results[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return SearchProductsResponseValidationError{
+					field:  fmt.Sprintf("Results[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	// no validation rules for Total
+
+	// no validation rules for NextPageToken
+
+	if len(errors) > 0 {
+		return SearchProductsResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// SearchProductsResponseMultiError is an error wrapping multiple validation
+// errors returned by SearchProductsResponse.ValidateAll() if the designated
+// constraints aren't met.
+type SearchProductsResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m SearchProductsResponseMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m SearchProductsResponseMultiError) AllErrors() []error { return m }
+
+// SearchProductsResponseValidationError is the validation error returned by
+// SearchProductsResponse.Validate if the designated constraints aren't met.
+type SearchProductsResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e SearchProductsResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e SearchProductsResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e SearchProductsResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e SearchProductsResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e SearchProductsResponseValidationError) ErrorName() string {
+	return "SearchProductsResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e SearchProductsResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sSearchProductsResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = SearchProductsResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = SearchProductsResponseValidationError{}
+
+// Validate checks the field values on UpdateProductRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *UpdateProductRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on UpdateProductRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// UpdateProductRequestMultiError, or nil if none found.
+func (m *UpdateProductRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *UpdateProductRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if err := m._validateUuid(m.GetId()); err != nil {
+		err = UpdateProductRequestValidationError{
+			field:  "Id",
+			reason: "value must be a valid UUID",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if utf8.RuneCountInString(m.GetName()) > 255 {
+		err := UpdateProductRequestValidationError{
+			field:  "Name",
+			reason: "value length must be at most 255 runes",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	// no validation rules for Description
+
+	if val := m.GetPrice(); val < 0 || val >= 1e+08 {
+		err := UpdateProductRequestValidationError{
+			field:  "Price",
+			reason: "value must be inside range [0, 1e+08)",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if all {
+		switch v := interface{}(m.GetUpdateMask()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, UpdateProductRequestValidationError{
+					field:  "UpdateMask",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, UpdateProductRequestValidationError{
+					field:  "UpdateMask",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetUpdateMask()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return UpdateProductRequestValidationError{
+				field:  "UpdateMask",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if utf8.RuneCountInString(m.GetEtag()) > 64 {
+		err := UpdateProductRequestValidationError{
+			field:  "Etag",
+			reason: "value length must be at most 64 runes",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return UpdateProductRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+func (m *UpdateProductRequest) _validateUuid(uuid string) error {
+	if matched := _product_uuidPattern.MatchString(uuid); !matched {
+		return errors.New("invalid uuid format")
+	}
+
+	return nil
+}
+
+// UpdateProductRequestMultiError is an error wrapping multiple validation
+// errors returned by UpdateProductRequest.ValidateAll() if the designated
+// constraints aren't met.
+type UpdateProductRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m UpdateProductRequestMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m UpdateProductRequestMultiError) AllErrors() []error { return m }
+
+// UpdateProductRequestValidationError is the validation error returned by
+// UpdateProductRequest.Validate if the designated constraints aren't met.
+type UpdateProductRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e UpdateProductRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e UpdateProductRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e UpdateProductRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e UpdateProductRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e UpdateProductRequestValidationError) ErrorName() string {
+	return "UpdateProductRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e UpdateProductRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sUpdateProductRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = UpdateProductRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = UpdateProductRequestValidationError{}
+
+// Validate checks the field values on DeleteProductRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *DeleteProductRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on DeleteProductRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// DeleteProductRequestMultiError, or nil if none found.
+func (m *DeleteProductRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *DeleteProductRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if err := m._validateUuid(m.GetId()); err != nil {
+		err = DeleteProductRequestValidationError{
+			field:  "Id",
+			reason: "value must be a valid UUID",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if utf8.RuneCountInString(m.GetEtag()) > 64 {
+		err := DeleteProductRequestValidationError{
+			field:  "Etag",
+			reason: "value length must be at most 64 runes",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return DeleteProductRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+func (m *DeleteProductRequest) _validateUuid(uuid string) error {
+	if matched := _product_uuidPattern.MatchString(uuid); !matched {
+		return errors.New("invalid uuid format")
+	}
+
+	return nil
+}
+
+// DeleteProductRequestMultiError is an error wrapping multiple validation
+// errors returned by DeleteProductRequest.ValidateAll() if the designated
+// constraints aren't met.
+type DeleteProductRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m DeleteProductRequestMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m DeleteProductRequestMultiError) AllErrors() []error { return m }
+
+// DeleteProductRequestValidationError is the validation error returned by
+// DeleteProductRequest.Validate if the designated constraints aren't met.
+type DeleteProductRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e DeleteProductRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e DeleteProductRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e DeleteProductRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e DeleteProductRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e DeleteProductRequestValidationError) ErrorName() string {
+	return "DeleteProductRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e DeleteProductRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sDeleteProductRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = DeleteProductRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = DeleteProductRequestValidationError{}
+
+// Validate checks the field values on DeleteProductResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *DeleteProductResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on DeleteProductResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// DeleteProductResponseMultiError, or nil if none found.
+func (m *DeleteProductResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *DeleteProductResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Success
+
+	if len(errors) > 0 {
+		return DeleteProductResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// DeleteProductResponseMultiError is an error wrapping multiple validation
+// errors returned by DeleteProductResponse.ValidateAll() if the designated
+// constraints aren't met.
+type DeleteProductResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m DeleteProductResponseMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m DeleteProductResponseMultiError) AllErrors() []error { return m }
+
+// DeleteProductResponseValidationError is the validation error returned by
+// DeleteProductResponse.Validate if the designated constraints aren't met.
+type DeleteProductResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e DeleteProductResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e DeleteProductResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e DeleteProductResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e DeleteProductResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e DeleteProductResponseValidationError) ErrorName() string {
+	return "DeleteProductResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e DeleteProductResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sDeleteProductResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = DeleteProductResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = DeleteProductResponseValidationError{}
+
+// Validate checks the field values on StockMovement with the rules defined in
+// the proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *StockMovement) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on StockMovement with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in StockMovementMultiError, or
+// nil if none found.
+func (m *StockMovement) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *StockMovement) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Id
+
+	// no validation rules for ProductId
+
+	// no validation rules for Type
+
+	// no validation rules for Quantity
+
+	// no validation rules for StockAfter
+
+	// no validation rules for Reason
+
+	// no validation rules for Actor
+
+	// no validation rules for Reference
+
+	// no validation rules for CreatedAt
+
+	if len(errors) > 0 {
+		return StockMovementMultiError(errors)
+	}
+
+	return nil
+}
+
+// StockMovementMultiError is an error wrapping multiple validation errors
+// returned by StockMovement.ValidateAll() if the designated constraints
+// aren't met.
+type StockMovementMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m StockMovementMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m StockMovementMultiError) AllErrors() []error { return m }
+
+// StockMovementValidationError is the validation error returned by
+// StockMovement.Validate if the designated constraints aren't met.
+type StockMovementValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e StockMovementValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e StockMovementValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e StockMovementValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e StockMovementValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e StockMovementValidationError) ErrorName() string { return "StockMovementValidationError" }
+
+// Error satisfies the builtin error interface
+func (e StockMovementValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sStockMovement.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = StockMovementValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = StockMovementValidationError{}
+
+// Validate checks the field values on AdjustStockRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *AdjustStockRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on AdjustStockRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// AdjustStockRequestMultiError, or nil if none found.
+func (m *AdjustStockRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *AdjustStockRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if err := m._validateUuid(m.GetProductId()); err != nil {
+		err = AdjustStockRequestValidationError{
+			field:  "ProductId",
+			reason: "value must be a valid UUID",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if _, ok := _AdjustStockRequest_Type_NotInLookup[m.GetType()]; ok {
+		err := AdjustStockRequestValidationError{
+			field:  "Type",
+			reason: "value must not be in list [STOCK_MOVEMENT_TYPE_UNSPECIFIED]",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if _, ok := StockMovementType_name[int32(m.GetType())]; !ok {
+		err := AdjustStockRequestValidationError{
+			field:  "Type",
+			reason: "value must be one of the defined enum values",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	// no validation rules for Quantity
+
+	if utf8.RuneCountInString(m.GetReason()) > 500 {
+		err := AdjustStockRequestValidationError{
+			field:  "Reason",
+			reason: "value length must be at most 500 runes",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if utf8.RuneCountInString(m.GetReference()) > 255 {
+		err := AdjustStockRequestValidationError{
+			field:  "Reference",
+			reason: "value length must be at most 255 runes",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return AdjustStockRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+func (m *AdjustStockRequest) _validateUuid(uuid string) error {
+	if matched := _product_uuidPattern.MatchString(uuid); !matched {
+		return errors.New("invalid uuid format")
+	}
+
+	return nil
+}
+
+// AdjustStockRequestMultiError is an error wrapping multiple validation errors
+// returned by AdjustStockRequest.ValidateAll() if the designated constraints
+// aren't met.
+type AdjustStockRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m AdjustStockRequestMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m AdjustStockRequestMultiError) AllErrors() []error { return m }
+
+// AdjustStockRequestValidationError is the validation error returned by
+// AdjustStockRequest.Validate if the designated constraints aren't met.
+type AdjustStockRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e AdjustStockRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e AdjustStockRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e AdjustStockRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e AdjustStockRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e AdjustStockRequestValidationError) ErrorName() string {
+	return "AdjustStockRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e AdjustStockRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sAdjustStockRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = AdjustStockRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = AdjustStockRequestValidationError{}
+
+var _AdjustStockRequest_Type_NotInLookup = map[StockMovementType]struct{}{
+	0: {},
+}
+
+// Validate checks the field values on AdjustStockResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *AdjustStockResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on AdjustStockResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// AdjustStockResponseMultiError, or nil if none found.
+func (m *AdjustStockResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *AdjustStockResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if all {
+		switch v := interface{}(m.GetProduct()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, AdjustStockResponseValidationError{
+					field:  "Product",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, AdjustStockResponseValidationError{
+					field:  "Product",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetProduct()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return AdjustStockResponseValidationError{
+				field:  "Product",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetMovement()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, AdjustStockResponseValidationError{
+					field:  "Movement",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, AdjustStockResponseValidationError{
+					field:  "Movement",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetMovement()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return AdjustStockResponseValidationError{
+				field:  "Movement",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return AdjustStockResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// AdjustStockResponseMultiError is an error wrapping multiple validation
+// errors returned by AdjustStockResponse.ValidateAll() if the designated
+// constraints aren't met.
+type AdjustStockResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m AdjustStockResponseMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m AdjustStockResponseMultiError) AllErrors() []error { return m }
+
+// AdjustStockResponseValidationError is the validation error returned by
+// AdjustStockResponse.Validate if the designated constraints aren't met.
+type AdjustStockResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e AdjustStockResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e AdjustStockResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e AdjustStockResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e AdjustStockResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e AdjustStockResponseValidationError) ErrorName() string {
+	return "AdjustStockResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e AdjustStockResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sAdjustStockResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = AdjustStockResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = AdjustStockResponseValidationError{}
+
+// Validate checks the field values on ListStockMovementsRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *ListStockMovementsRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ListStockMovementsRequest with the
+// rules defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// ListStockMovementsRequestMultiError, or nil if none found.
+func (m *ListStockMovementsRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ListStockMovementsRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if err := m._validateUuid(m.GetProductId()); err != nil {
+		err = ListStockMovementsRequestValidationError{
+			field:  "ProductId",
+			reason: "value must be a valid UUID",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if m.GetLimit() < 0 {
+		err := ListStockMovementsRequestValidationError{
+			field:  "Limit",
+			reason: "value must be greater than or equal to 0",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if utf8.RuneCountInString(m.GetPageToken()) > 512 {
+		err := ListStockMovementsRequestValidationError{
+			field:  "PageToken",
+			reason: "value length must be at most 512 runes",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	// no validation rules for IncludeTotal
+
+	if utf8.RuneCountInString(m.GetFilter()) > 1024 {
+		err := ListStockMovementsRequestValidationError{
+			field:  "Filter",
+			reason: "value length must be at most 1024 runes",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return ListStockMovementsRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+func (m *ListStockMovementsRequest) _validateUuid(uuid string) error {
+	if matched := _product_uuidPattern.MatchString(uuid); !matched {
+		return errors.New("invalid uuid format")
+	}
+
+	return nil
+}
+
+// ListStockMovementsRequestMultiError is an error wrapping multiple validation
+// errors returned by ListStockMovementsRequest.ValidateAll() if the
+// designated constraints aren't met.
+type ListStockMovementsRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ListStockMovementsRequestMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ListStockMovementsRequestMultiError) AllErrors() []error { return m }
+
+// ListStockMovementsRequestValidationError is the validation error returned by
+// ListStockMovementsRequest.Validate if the designated constraints aren't met.
+type ListStockMovementsRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ListStockMovementsRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ListStockMovementsRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ListStockMovementsRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ListStockMovementsRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ListStockMovementsRequestValidationError) ErrorName() string {
+	return "ListStockMovementsRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e ListStockMovementsRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sListStockMovementsRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ListStockMovementsRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ListStockMovementsRequestValidationError{}
+
+// Validate checks the field values on StockReservation with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// first error encountered is returned, or nil if there are no violations.
+func (m *StockReservation) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on StockReservation with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// StockReservationMultiError, or nil if none found.
+func (m *StockReservation) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *StockReservation) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Id
+
+	// no validation rules for ProductId
+
+	// no validation rules for Quantity
+
+	// no validation rules for Status
+
+	// no validation rules for Reference
+
+	// no validation rules for Actor
+
+	// no validation rules for ExpiresAt
+
+	// no validation rules for CreatedAt
+
+	// no validation rules for UpdatedAt
+
+	if len(errors) > 0 {
+		return StockReservationMultiError(errors)
+	}
+
+	return nil
+}
+
+// StockReservationMultiError is an error wrapping multiple validation errors
+// returned by StockReservation.ValidateAll() if the designated constraints
+// aren't met.
+type StockReservationMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m StockReservationMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m StockReservationMultiError) AllErrors() []error { return m }
+
+// StockReservationValidationError is the validation error returned by
+// StockReservation.Validate if the designated constraints aren't met.
+type StockReservationValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e StockReservationValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e StockReservationValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e StockReservationValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e StockReservationValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e StockReservationValidationError) ErrorName() string { return "StockReservationValidationError" }
+
+// Error satisfies the builtin error interface
+func (e StockReservationValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sStockReservation.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = StockReservationValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = StockReservationValidationError{}
+
+// Validate checks the field values on ReserveStockRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *ReserveStockRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ReserveStockRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// ReserveStockRequestMultiError, or nil if none found.
+func (m *ReserveStockRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ReserveStockRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if err := m._validateUuid(m.GetProductId()); err != nil {
+		err = ReserveStockRequestValidationError{
+			field:  "ProductId",
+			reason: "value must be a valid UUID",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if m.GetQuantity() <= 0 {
+		err := ReserveStockRequestValidationError{
+			field:  "Quantity",
+			reason: "value must be greater than 0",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if val := m.GetTtlSeconds(); val < 0 || val > 86400 {
+		err := ReserveStockRequestValidationError{
+			field:  "TtlSeconds",
+			reason: "value must be inside range [0, 86400]",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if utf8.RuneCountInString(m.GetReference()) > 255 {
+		err := ReserveStockRequestValidationError{
+			field:  "Reference",
+			reason: "value length must be at most 255 runes",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return ReserveStockRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+func (m *ReserveStockRequest) _validateUuid(uuid string) error {
+	if matched := _product_uuidPattern.MatchString(uuid); !matched {
+		return errors.New("invalid uuid format")
+	}
+
+	return nil
+}
+
+// ReserveStockRequestMultiError is an error wrapping multiple validation
+// errors returned by ReserveStockRequest.ValidateAll() if the designated
+// constraints aren't met.
+type ReserveStockRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ReserveStockRequestMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ReserveStockRequestMultiError) AllErrors() []error { return m }
+
+// ReserveStockRequestValidationError is the validation error returned by
+// ReserveStockRequest.Validate if the designated constraints aren't met.
+type ReserveStockRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ReserveStockRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ReserveStockRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ReserveStockRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ReserveStockRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ReserveStockRequestValidationError) ErrorName() string {
+	return "ReserveStockRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e ReserveStockRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sReserveStockRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ReserveStockRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ReserveStockRequestValidationError{}
+
+// Validate checks the field values on ReserveStockResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *ReserveStockResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ReserveStockResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// ReserveStockResponseMultiError, or nil if none found.
+func (m *ReserveStockResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ReserveStockResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if all {
+		switch v := interface{}(m.GetReservation()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, ReserveStockResponseValidationError{
+					field:  "Reservation",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, ReserveStockResponseValidationError{
+					field:  "Reservation",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetReservation()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ReserveStockResponseValidationError{
+				field:  "Reservation",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetProduct()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, ReserveStockResponseValidationError{
+					field:  "Product",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, ReserveStockResponseValidationError{
+					field:  "Product",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetProduct()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ReserveStockResponseValidationError{
+				field:  "Product",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return ReserveStockResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// ReserveStockResponseMultiError is an error wrapping multiple validation
+// errors returned by ReserveStockResponse.ValidateAll() if the designated
+// constraints aren't met.
+type ReserveStockResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ReserveStockResponseMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ReserveStockResponseMultiError) AllErrors() []error { return m }
+
+// ReserveStockResponseValidationError is the validation error returned by
+// ReserveStockResponse.Validate if the designated constraints aren't met.
+type ReserveStockResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ReserveStockResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ReserveStockResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ReserveStockResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ReserveStockResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ReserveStockResponseValidationError) ErrorName() string {
+	return "ReserveStockResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e ReserveStockResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sReserveStockResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ReserveStockResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ReserveStockResponseValidationError{}
+
+// Validate checks the field values on CommitReservationRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *CommitReservationRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on CommitReservationRequest with the
+// rules defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// CommitReservationRequestMultiError, or nil if none found.
+func (m *CommitReservationRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *CommitReservationRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if err := m._validateUuid(m.GetId()); err != nil {
+		err = CommitReservationRequestValidationError{
+			field:  "Id",
+			reason: "value must be a valid UUID",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return CommitReservationRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+func (m *CommitReservationRequest) _validateUuid(uuid string) error {
+	if matched := _product_uuidPattern.MatchString(uuid); !matched {
+		return errors.New("invalid uuid format")
+	}
+
+	return nil
+}
+
+// CommitReservationRequestMultiError is an error wrapping multiple validation
+// errors returned by CommitReservationRequest.ValidateAll() if the designated
+// constraints aren't met.
+type CommitReservationRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m CommitReservationRequestMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m CommitReservationRequestMultiError) AllErrors() []error { return m }
+
+// CommitReservationRequestValidationError is the validation error returned by
+// CommitReservationRequest.Validate if the designated constraints aren't met.
+type CommitReservationRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e CommitReservationRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e CommitReservationRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e CommitReservationRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e CommitReservationRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e CommitReservationRequestValidationError) ErrorName() string {
+	return "CommitReservationRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e CommitReservationRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sCommitReservationRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = CommitReservationRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = CommitReservationRequestValidationError{}
+
+// Validate checks the field values on CommitReservationResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *CommitReservationResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on CommitReservationResponse with the
+// rules defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// CommitReservationResponseMultiError, or nil if none found.
+func (m *CommitReservationResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *CommitReservationResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if all {
+		switch v := interface{}(m.GetReservation()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, CommitReservationResponseValidationError{
+					field:  "Reservation",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, CommitReservationResponseValidationError{
+					field:  "Reservation",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetReservation()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return CommitReservationResponseValidationError{
+				field:  "Reservation",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetProduct()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, CommitReservationResponseValidationError{
+					field:  "Product",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, CommitReservationResponseValidationError{
+					field:  "Product",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetProduct()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return CommitReservationResponseValidationError{
+				field:  "Product",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return CommitReservationResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// CommitReservationResponseMultiError is an error wrapping multiple validation
+// errors returned by CommitReservationResponse.ValidateAll() if the
+// designated constraints aren't met.
+type CommitReservationResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m CommitReservationResponseMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m CommitReservationResponseMultiError) AllErrors() []error { return m }
+
+// CommitReservationResponseValidationError is the validation error returned by
+// CommitReservationResponse.Validate if the designated constraints aren't met.
+type CommitReservationResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e CommitReservationResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e CommitReservationResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e CommitReservationResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e CommitReservationResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e CommitReservationResponseValidationError) ErrorName() string {
+	return "CommitReservationResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e CommitReservationResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sCommitReservationResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = CommitReservationResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = CommitReservationResponseValidationError{}
+
+// Validate checks the field values on ReleaseReservationRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *ReleaseReservationRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ReleaseReservationRequest with the
+// rules defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// ReleaseReservationRequestMultiError, or nil if none found.
+func (m *ReleaseReservationRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ReleaseReservationRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if err := m._validateUuid(m.GetId()); err != nil {
+		err = ReleaseReservationRequestValidationError{
+			field:  "Id",
+			reason: "value must be a valid UUID",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return ReleaseReservationRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+func (m *ReleaseReservationRequest) _validateUuid(uuid string) error {
+	if matched := _product_uuidPattern.MatchString(uuid); !matched {
+		return errors.New("invalid uuid format")
+	}
+
+	return nil
+}
+
+// ReleaseReservationRequestMultiError is an error wrapping multiple validation
+// errors returned by ReleaseReservationRequest.ValidateAll() if the
+// designated constraints aren't met.
+type ReleaseReservationRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ReleaseReservationRequestMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ReleaseReservationRequestMultiError) AllErrors() []error { return m }
+
+// ReleaseReservationRequestValidationError is the validation error returned by
+// ReleaseReservationRequest.Validate if the designated constraints aren't met.
+type ReleaseReservationRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ReleaseReservationRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ReleaseReservationRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ReleaseReservationRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ReleaseReservationRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ReleaseReservationRequestValidationError) ErrorName() string {
+	return "ReleaseReservationRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e ReleaseReservationRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sReleaseReservationRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ReleaseReservationRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ReleaseReservationRequestValidationError{}
+
+// Validate checks the field values on ReleaseReservationResponse with the
+// rules defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *ReleaseReservationResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ReleaseReservationResponse with the
+// rules defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// ReleaseReservationResponseMultiError, or nil if none found.
+func (m *ReleaseReservationResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ReleaseReservationResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if all {
+		switch v := interface{}(m.GetReservation()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, ReleaseReservationResponseValidationError{
+					field:  "Reservation",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, ReleaseReservationResponseValidationError{
+					field:  "Reservation",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetReservation()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ReleaseReservationResponseValidationError{
+				field:  "Reservation",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if all {
+		switch v := interface{}(m.GetProduct()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, ReleaseReservationResponseValidationError{
+					field:  "Product",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, ReleaseReservationResponseValidationError{
+					field:  "Product",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetProduct()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return ReleaseReservationResponseValidationError{
+				field:  "Product",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return ReleaseReservationResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// ReleaseReservationResponseMultiError is an error wrapping multiple
+// validation errors returned by ReleaseReservationResponse.ValidateAll() if
+// the designated constraints aren't met.
+type ReleaseReservationResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ReleaseReservationResponseMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ReleaseReservationResponseMultiError) AllErrors() []error { return m }
+
+// ReleaseReservationResponseValidationError is the validation error returned
+// by ReleaseReservationResponse.Validate if the designated constraints aren't met.
+type ReleaseReservationResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ReleaseReservationResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ReleaseReservationResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ReleaseReservationResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ReleaseReservationResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ReleaseReservationResponseValidationError) ErrorName() string {
+	return "ReleaseReservationResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e ReleaseReservationResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sReleaseReservationResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ReleaseReservationResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ReleaseReservationResponseValidationError{}
+
+// Validate checks the field values on ListStockMovementsResponse with the
+// rules defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *ListStockMovementsResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on ListStockMovementsResponse with the
+// rules defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// ListStockMovementsResponseMultiError, or nil if none found.
+func (m *ListStockMovementsResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *ListStockMovementsResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	for idx, item := range m.GetMovements() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, ListStockMovementsResponseValidationError{
+						field:  fmt.Sprintf("Movements[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, ListStockMovementsResponseValidationError{
+						field:  fmt.Sprintf("Movements[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return ListStockMovementsResponseValidationError{
+					field:  fmt.Sprintf("Movements[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	// no validation rules for Total
+
+	// no validation rules for NextPageToken
+
+	if len(errors) > 0 {
+		return ListStockMovementsResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// ListStockMovementsResponseMultiError is an error wrapping multiple
+// validation errors returned by ListStockMovementsResponse.ValidateAll() if
+// the designated constraints aren't met.
+type ListStockMovementsResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m ListStockMovementsResponseMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m ListStockMovementsResponseMultiError) AllErrors() []error { return m }
+
+// ListStockMovementsResponseValidationError is the validation error returned
+// by ListStockMovementsResponse.Validate if the designated constraints aren't met.
+type ListStockMovementsResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e ListStockMovementsResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e ListStockMovementsResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e ListStockMovementsResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e ListStockMovementsResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e ListStockMovementsResponseValidationError) ErrorName() string {
+	return "ListStockMovementsResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e ListStockMovementsResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sListStockMovementsResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = ListStockMovementsResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = ListStockMovementsResponseValidationError{}